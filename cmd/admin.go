@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// adminFlags holds the flags locating the admin API of a running server
+type adminFlags struct {
+	url     *string
+	prefix  *string
+	token   *string
+	timeout *time.Duration
+}
+
+// addAdminFlags registers the '--url', '--prefix', '--token' and '--timeout' flags with the given flag set
+func addAdminFlags(flags *flag.FlagSet, timeout time.Duration) *adminFlags {
+	return &adminFlags{
+		url:     flags.String("url", "http://localhost:8080", "URL of the running server, or of its admin port"),
+		prefix:  flags.String("prefix", config.DefaultAdminPrefix, "Path the admin API is served under"),
+		token:   flags.String("token", "", "Admin bearer token, defaults to $MINISTUB_ADMIN_TOKEN"),
+		timeout: flags.Duration("timeout", timeout, "Time to wait for the admin API to respond, 0 waits indefinitely"),
+	}
+}
+
+// request sends a request to a route of the admin API, returning the status code and body of the response
+func (a *adminFlags) request(method, route string, body []byte) (int, []byte, error) {
+	url := fmt.Sprintf("%s%s/v1%s", strings.TrimSuffix(*a.url, "/"), strings.TrimSuffix(*a.prefix, "/"), route)
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, fmt.Errorf("Unable To Create Request: %s", err.Error())
+	}
+
+	// the environment is read once the flags are parsed, so the token is never printed as a default
+	token := *a.token
+	if len(token) == 0 {
+		token = os.Getenv("MINISTUB_ADMIN_TOKEN")
+	}
+	if len(token) > 0 {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	client := &http.Client{Timeout: *a.timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Unable To Reach Admin API At %s: %s", url, err.Error())
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Unable To Read Response: %s", err.Error())
+	}
+	return resp.StatusCode, data, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// configUsage is printed when the config command is given invalid args
+const configUsage = "Usage:\nministub config print [path] [-f {path}]... [--profile {name}] [--format yaml|json] [--expand]\n"
+
+// pathList is a flag which may be given more than once
+type pathList []string
+
+// String returns the paths given
+func (p *pathList) String() string { return strings.Join(*p, ", ") }
+
+// Set appends a path
+func (p *pathList) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// overlayFlags holds the flags selecting the overlays merged into a config
+type overlayFlags struct {
+	files   pathList
+	profile *string
+}
+
+// addOverlayFlags registers the '-f' and '--profile' flags with the given flag set
+func addOverlayFlags(flags *flag.FlagSet) *overlayFlags {
+	overlays := new(overlayFlags)
+	flags.Var(&overlays.files, "f", "Config file, the first is the base and each following file an overlay, may be repeated")
+	overlays.profile = flags.String("profile", "", "Profile overlay to merge, 'ci' selects ministub.ci.yml for ministub.yml")
+	return overlays
+}
+
+// paths returns the base path then each overlay, the base is the first '-f' file when not given
+func (o *overlayFlags) paths(base string) ([]string, error) {
+	return configPaths(base, o.files, *o.profile)
+}
+
+// configPaths returns the base path, the given overlays, then the overlay of the profile when one is named
+func configPaths(base string, files []string, profile string) ([]string, error) {
+	paths := files
+	if len(base) > 0 {
+		paths = append([]string{base}, files...)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("No Config Path Given")
+	}
+
+	if len(profile) > 0 {
+		info, err := os.Stat(paths[0])
+		paths = append(paths, config.ProfilePath(paths[0], profile, err == nil && info.IsDir()))
+	}
+	return paths, nil
+}
+
+// runConfig inspects a config
+func runConfig(log logger.Logger, args []string) {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprint(os.Stderr, configUsage)
+		os.Exit(1)
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ExitOnError)
+	overlays := addOverlayFlags(flags)
+	format := flags.String("format", "yaml", "Output format, yaml or json")
+	expand := flags.Bool("expand", false, "Expand '${...}' references")
+	inPath := parseFlagsWithOptionalPath(flags, args[1:], configUsage)
+
+	paths, err := overlays.paths(inPath)
+	if err != nil {
+		fmt.Fprint(os.Stderr, configUsage)
+		os.Exit(1)
+	}
+
+	load := config.LoadRawFromFiles
+	if *expand {
+		load = config.LoadFromFiles
+	}
+	cfg, err := load(paths)
+	if err != nil {
+		logConfigErrors(log, "Unable To Load Config", err)
+		os.Exit(1)
+	}
+
+	var data []byte
+	switch {
+	case *format == "yaml":
+		data, err = yaml.Marshal(cfg)
+	case *format == "json":
+		data, err = json.MarshalIndent(config.ToJSON(cfg), "", "  ")
+		data = append(data, '\n')
+	default:
+		logFatal(log, fmt.Sprintf("Unknown Format: %s", *format))
+	}
+	if err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Marshal Config: %s", err.Error()))
+	}
+
+	os.Stdout.Write(data)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/convert"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// exportUsage is printed when the export command is given invalid args
+const exportUsage = "Usage:\nministub export openapi {path} [--format yaml|json] [--api-version {version}] [--out {path}]\n"
+
+// runExport converts a ministub config into an API definition in another format
+func runExport(log logger.Logger, args []string) {
+	if len(args) == 0 || args[0] != "openapi" {
+		fmt.Fprint(os.Stderr, exportUsage)
+		os.Exit(1)
+	}
+
+	flags := flag.NewFlagSet("export openapi", flag.ExitOnError)
+	format := flags.String("format", "yaml", "Output format, yaml or json")
+	version := flags.String("api-version", "1.0.0", "Version of the API described, written to info.version")
+	outPath := flags.String("out", "", "Path to write the spec to, defaults to stdout")
+	inPath := parseFlagsWithPath(flags, args[1:], exportUsage)
+
+	cfg, err := config.LoadRawFromFile(inPath)
+	if err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Load Config %s: %s", inPath, err.Error()))
+	}
+
+	title := strings.TrimSuffix(filepath.Base(inPath), filepath.Ext(inPath))
+	spec := convert.ToOpenAPI(cfg, title, *version)
+
+	var data []byte
+	switch {
+	case *format == "yaml":
+		data, err = yaml.Marshal(spec)
+	case *format == "json":
+		data, err = json.MarshalIndent(spec, "", "  ")
+		data = append(data, '\n')
+	default:
+		logFatal(log, fmt.Sprintf("Unknown Format: %s", *format))
+	}
+	if err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Marshal Spec: %s", err.Error()))
+	}
+
+	writeOutput(log, data, *outPath)
+}
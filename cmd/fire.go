@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/api"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// fireUsage is printed when the fire command is given invalid args
+const fireUsage = "Usage:\nministub fire {request-id} --target {service} [--url {url}] [--prefix {path}] [--token {token}] [--timeout {duration}] [--format human|json]\nministub fire --file {path} [--url {url}] [--prefix {path}] [--token {token}] [--timeout {duration}] [--format human|json]\n"
+
+// runFire has a running server send a configured request, or run a list of actions, exiting non-zero if any fail
+func runFire(log logger.Logger, args []string) {
+	flags := flag.NewFlagSet("fire", flag.ExitOnError)
+	// the server responds once every action has run, so delays are waited for by default
+	admin := addAdminFlags(flags, 0)
+	fire := new(api.Fire)
+	flags.StringVar(&fire.Target, "target", "", "Service the request is sent to")
+	file := flags.String("file", "", "YAML or JSON file holding a list of 'actions' to run, in place of a request")
+	format := flags.String("format", "human", "Output format, human or json")
+	fire.Request = parseFlagsWithOptionalPath(flags, args, fireUsage)
+
+	if (len(fire.Request) == 0) == (len(*file) == 0) {
+		fmt.Fprint(os.Stderr, fireUsage)
+		os.Exit(1)
+	}
+	if *format != "human" && *format != "json" {
+		logFatal(log, fmt.Sprintf("Unknown Format: %s", *format))
+	}
+
+	content, err := yaml.Marshal(fire)
+	if len(*file) > 0 {
+		content, err = ioutil.ReadFile(*file)
+	}
+	if err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Read Actions: %s", err.Error()))
+	}
+
+	statusCode, data, err := admin.request(http.MethodPost, "/fire", content)
+	if err != nil {
+		logFatal(log, err.Error())
+	}
+	if statusCode != http.StatusOK {
+		logFatal(log, fmt.Sprintf("Fire Returned %d: %s", statusCode, strings.TrimSpace(string(data))))
+	}
+
+	result := new(api.FireResult)
+	if err := json.Unmarshal(data, result); err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Decode Fire Result: %s", err.Error()))
+	}
+
+	if *format == "json" {
+		output, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(output))
+	} else {
+		for _, entry := range result.Results {
+			fmt.Println(actionResultLine(entry))
+		}
+	}
+
+	if !result.Success {
+		os.Exit(1)
+	}
+}
+
+// actionResultLine describes the outcome of a single action
+func actionResultLine(result *api.ActionResult) string {
+	switch {
+	case result.Action == "delay":
+		return fmt.Sprintf("Delayed %d Seconds", result.Delay)
+	case result.Success:
+		return fmt.Sprintf("Request %s To Service %s Succeeded", result.Request, result.Target)
+	default:
+		return fmt.Sprintf("Request %s To Service %s Failed: %s", result.Request, result.Target, result.Error)
+	}
+}
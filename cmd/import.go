@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/convert"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// importUsage is printed when the import command is given invalid args
+const importUsage = "Usage:\nministub import har {path} [--host {host}] [--dedupe] [--out {path}]\nministub import openapi {path} [--out {path}]\nministub import postman {path} [--out {path}]\n"
+
+// runImport converts an API definition in another format into a ministub config
+func runImport(log logger.Logger, args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, importUsage)
+		os.Exit(1)
+	}
+
+	switch {
+	case args[0] == "har":
+		flags := flag.NewFlagSet("import har", flag.ExitOnError)
+		host := flags.String("host", "", "Only import requests sent to this host")
+		dedupe := flags.Bool("dedupe", false, "Collapse paths differing only by ID segments into one endpoint")
+		matchHeaders := flags.Bool("match-headers", false, "Require request headers sent identically on every call to an endpoint")
+		outPath := flags.String("out", "", "Path to write the config to, defaults to stdout")
+		inPath := parseFlagsWithPath(flags, args[1:], importUsage)
+
+		data, err := ioutil.ReadFile(inPath)
+		if err != nil {
+			logFatal(log, fmt.Sprintf("Unable To Read File %s: %s", inPath, err.Error()))
+		}
+
+		cfg, err := convert.FromHAR(data, &convert.HAROptions{Host: *host, Dedupe: *dedupe, MatchHeaders: *matchHeaders})
+		if err != nil {
+			logFatal(log, fmt.Sprintf("Unable To Import HAR %s: %s", inPath, err.Error()))
+		}
+		writeConfig(log, cfg, *outPath)
+	case args[0] == "openapi":
+		flags := flag.NewFlagSet("import openapi", flag.ExitOnError)
+		outPath := flags.String("out", "", "Path to write the config to, defaults to stdout")
+		inPath := parseFlagsWithPath(flags, args[1:], importUsage)
+
+		data, err := ioutil.ReadFile(inPath)
+		if err != nil {
+			logFatal(log, fmt.Sprintf("Unable To Read File %s: %s", inPath, err.Error()))
+		}
+
+		cfg, err := convert.FromOpenAPI(data)
+		if err != nil {
+			logFatal(log, fmt.Sprintf("Unable To Import OpenAPI Spec %s: %s", inPath, err.Error()))
+		}
+		writeConfig(log, cfg, *outPath)
+	case args[0] == "postman":
+		flags := flag.NewFlagSet("import postman", flag.ExitOnError)
+		outPath := flags.String("out", "", "Path to write the config to, defaults to stdout")
+		inPath := parseFlagsWithPath(flags, args[1:], importUsage)
+
+		data, err := ioutil.ReadFile(inPath)
+		if err != nil {
+			logFatal(log, fmt.Sprintf("Unable To Read File %s: %s", inPath, err.Error()))
+		}
+
+		cfg, warnings, err := convert.FromPostman(data)
+		if err != nil {
+			logFatal(log, fmt.Sprintf("Unable To Import Postman Collection %s: %s", inPath, err.Error()))
+		}
+		for _, msg := range warnings {
+			log.Info(fmt.Sprintf("Warning: %s", msg))
+		}
+		writeConfig(log, cfg, *outPath)
+	default:
+		fmt.Fprint(os.Stderr, importUsage)
+		os.Exit(1)
+	}
+}
+
+// parseFlagsWithPath parses the given flags, which may appear before or after a single required path argument
+func parseFlagsWithPath(flags *flag.FlagSet, args []string, usage string) string {
+	path := parseFlagsWithOptionalPath(flags, args, usage)
+	if len(path) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+	return path
+}
+
+// parseFlagsWithOptionalPath parses the given flags, which may appear before or after a single path argument
+func parseFlagsWithOptionalPath(flags *flag.FlagSet, args []string, usage string) string {
+	var path string
+	for len(args) > 0 {
+		flags.Parse(args)
+		if args = flags.Args(); len(args) > 0 {
+			if len(path) > 0 {
+				fmt.Fprint(os.Stderr, usage)
+				os.Exit(1)
+			}
+			path, args = args[0], args[1:]
+		}
+	}
+	return path
+}
+
+// writeConfig marshals the given config to YAML and writes it to the given path, or stdout if the path is empty
+func writeConfig(log logger.Logger, cfg *config.Config, path string) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Marshal Config: %s", err.Error()))
+	}
+	writeOutput(log, data, path)
+}
+
+// writeOutput writes the given data to the given path, or stdout if the path is empty
+func writeOutput(log logger.Logger, data []byte, path string) {
+	if len(path) == 0 {
+		os.Stdout.Write(data)
+		return
+	}
+
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Write File %s: %s", path, err.Error()))
+	}
+	log.Info(fmt.Sprintf("Config Written To Path: %s", path))
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+// lintUsage is printed when the lint command is given invalid args
+const lintUsage = "Usage:\nministub lint [path] [-f {path}]... [--profile {name}] [--format human|json]\n"
+
+// runLint validates a config then reports likely mistakes within it, exiting non-zero if any are found
+func runLint(log logger.Logger, args []string) {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	overlays := addOverlayFlags(flags)
+	format := flags.String("format", "human", "Output format, human or json")
+	paths, err := overlays.paths(parseFlagsWithOptionalPath(flags, args, lintUsage))
+	if err != nil {
+		fmt.Fprint(os.Stderr, lintUsage)
+		os.Exit(1)
+	}
+
+	if *format != "human" && *format != "json" {
+		logFatal(log, fmt.Sprintf("Unknown Format: %s", *format))
+	}
+
+	// a config must be valid before it can be linted
+	cfg, errs := loadAndValidate(paths)
+	if len(errs) > 0 {
+		reportErrors(errs, *format, "", "Error")
+	}
+
+	errs, _ = config.Lint(cfg).(config.ValidationErrors)
+	reportErrors(errs, *format, fmt.Sprintf("%s: No Problems Found", paths[0]), "Problem")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// migrateUsage is printed when the migrate command is given invalid args
+const migrateUsage = "Usage:\nministub migrate {path} [--out {path}] [--write]\n"
+
+// runMigrate converts a config to the latest schema version
+func runMigrate(log logger.Logger, args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	outPath := flags.String("out", "", "Path to write the config to, defaults to stdout")
+	write := flags.Bool("write", false, "Overwrite the given config, refused when it holds comments as they are not kept")
+	inPath := parseFlagsWithPath(flags, args, migrateUsage)
+
+	// the migrated config is generated from the decoded config, so comments and key order cannot be kept
+	if *write {
+		content, err := ioutil.ReadFile(inPath)
+		if err != nil {
+			logFatal(log, fmt.Sprintf("Unable To Read File %s: %s", inPath, err.Error()))
+		}
+		if config.HasComments(content) {
+			logFatal(log, fmt.Sprintf("Config %s Holds Comments Which Would Be Lost, Use --out To Write The Migrated Config Elsewhere", inPath))
+		}
+	}
+
+	cfg, err := config.LoadRawFromFile(inPath)
+	if err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Load Config %s: %s", inPath, err.Error()))
+	}
+
+	if err := config.Migrate(cfg); err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Migrate Config %s: %s", inPath, err.Error()))
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Marshal Config: %s", err.Error()))
+	}
+	// the migrated config must decode under the strict rules of the new version
+	if _, err := config.Parse(data); err != nil {
+		logFatal(log, fmt.Sprintf("Migrated Config Is Invalid: %s", err.Error()))
+	}
+
+	if *write {
+		*outPath = inPath
+	}
+	writeOutput(log, data, *outPath)
+}
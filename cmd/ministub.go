@@ -2,93 +2,198 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/MichaelWittgreffe/ministub/pkg/api"
 	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/convert"
 	"github.com/MichaelWittgreffe/ministub/pkg/logger"
 )
 
 func main() {
 	log := logger.NewLogger("std")
 
-	cfgPath, bindHost, port, err := parseArgs()
+	if len(os.Args) > 1 {
+		switch {
+		case os.Args[1] == "record":
+			runRecord(log, os.Args[2:])
+			return
+		case os.Args[1] == "import":
+			runImport(log, os.Args[2:])
+			return
+		case os.Args[1] == "export":
+			runExport(log, os.Args[2:])
+			return
+		case os.Args[1] == "migrate":
+			runMigrate(log, os.Args[2:])
+			return
+		case os.Args[1] == "validate":
+			runValidate(log, os.Args[2:])
+			return
+		case os.Args[1] == "lint":
+			runLint(log, os.Args[2:])
+			return
+		case os.Args[1] == "schema":
+			runSchema(log, os.Args[2:])
+			return
+		case os.Args[1] == "verify":
+			runVerify(log, os.Args[2:])
+			return
+		case os.Args[1] == "fire":
+			runFire(log, os.Args[2:])
+			return
+		case os.Args[1] == "config":
+			runConfig(log, os.Args[2:])
+			return
+		}
+	}
+
+	opts, err := parseArgs()
 	if err != nil {
 		logFatal(log, fmt.Sprintf("Startup Error: %s", err.Error()))
 	}
 
 	log.Info("Loading Config...")
 
-	cfg, err := config.LoadFromFile(cfgPath)
+	cfg, err := loadConfig(opts.cfgPaths)
 	if err != nil {
-		logFatal(log, fmt.Sprintf("Unable To Load From From Path %s: %s", cfgPath, err.Error()))
+		logConfigErrors(log, fmt.Sprintf("Unable To Load From From Path %s", strings.Join(opts.cfgPaths, ", ")), err)
+		os.Exit(1)
 	}
 
 	if err = config.Validate(cfg); err != nil {
-		logFatal(log, fmt.Sprintf("Config Validation Error: %s", err.Error()))
+		logConfigErrors(log, "Config Validation Error", err)
+		os.Exit(1)
 	}
 
-	log.Info(fmt.Sprintf("Config Loaded From Path: %s", cfgPath))
+	log.Info(fmt.Sprintf("Config Loaded From Path: %s", strings.Join(opts.cfgPaths, ", ")))
 
 	requester := api.NewRequester("http")
 
 	if server := api.NewHTTPAPI(log, cfg, requester); server != nil {
-		if cfg.StartupActions != nil && len(cfg.StartupActions) > 0 {
-			log.Info("Executing Startup Actions...")
-			go api.ExecuteActions(cfg.StartupActions, "Startup", cfg, log, requester)
+		for name, entry := range cfg.Listeners {
+			if entry.Protocol == "tcp" && entry.Port == opts.port {
+				logFatal(log, fmt.Sprintf("Listener %s Port %d Is Already Used By The HTTP API", name, entry.Port))
+			}
+			listener, err := api.NewSocketAPI(log, cfg, name, requester)
+			if err != nil {
+				logFatal(log, fmt.Sprintf("Unable To Create Listener %s: %s", name, err.Error()))
+			}
+			server.AddListener(listener)
+
+			go func() {
+				logFatal(log, fmt.Sprintf("Fatal Error On Listener %s: %s", listener.Name(), listener.ListenAndServe(opts.bind).Error()))
+			}()
 		}
 
+		go server.RunStartupActions()
+
+		go watchConfig(log, opts.cfgPaths, cfg, server, opts.watch, opts.keepStats)
+
 		logFatal(
 			log,
 			fmt.Sprintf(
 				"Fatal Error: %s",
-				server.ListenAndServe(bindHost, port).Error(),
+				server.ListenAndServe(opts.bind, opts.port).Error(),
 			),
 		)
 	}
 }
 
+// loadConfig loads the config at the given path with any overlays, an OpenAPI 3 spec is converted into a config so it can be served directly
+func loadConfig(paths []string) (*config.Config, error) {
+	if data, err := ioutil.ReadFile(paths[0]); err == nil && len(paths) == 1 && convert.IsOpenAPI(data) {
+		return convert.FromOpenAPI(data)
+	}
+	return config.LoadFromFiles(paths)
+}
+
 // logFatal prints the given message to the logger error stream then os.Exit(1)
 func logFatal(log logger.Logger, msg string) {
 	log.Error(msg)
 	os.Exit(1)
 }
 
+// options holds the args used to run the server
+type options struct {
+	cfgPaths  []string // the base config then each overlay
+	bind      string
+	port      int
+	watch     time.Duration // interval the config files are polled for changes, zero disables polling
+	keepStats bool          // keep stats for endpoints which still exist when the config is reloaded
+}
+
 // parseArgs parses the cmd args and returns
-func parseArgs() (cfgPath string, bind string, port int, err error) {
+func parseArgs() (*options, error) {
+	opts := &options{watch: 2 * time.Second}
+	var cfgPath, profile string
+	var files []string
+	var err error
+
+	// flags followed by a value, which is never taken as the config path
+	valueFlags := map[string]bool{"-p": true, "-b": true, "-w": true, "-f": true, "--profile": true}
+
 	for i, data := range os.Args {
+		var value string
+		if valueFlags[data] {
+			if i+1 >= len(os.Args) {
+				return nil, fmt.Errorf("Missing Value For %s", data)
+			}
+			value = os.Args[i+1]
+		}
+
 		switch {
 		case data == "-h":
-			fmt.Printf("ministub is an API stubbing tool allowing follow-on actions from an incoming request\n\nUsage:\nministub [path]\n\t-h: Help\n\t-p: Port\n\t-b: Accept Host\n")
+			fmt.Printf("ministub is an API stubbing tool allowing follow-on actions from an incoming request\n\nUsage:\nministub [path]\n\t-h: Help\n\t-p: Port\n\t-b: Accept Host\n\t-w: Seconds Between Checking Config For Changes, 0 Disables\n\t-k: Keep Stats On Reload\n\t-f: Config File, The First Is The Base And Each Following File An Overlay\n\t--profile: Profile Overlay To Merge\n\nCommands:\nministub record --upstream {url} --out {path}\nministub import har {path} [--host {host}] [--dedupe] [--out {path}]\nministub import openapi {path} [--out {path}]\nministub import postman {path} [--out {path}]\nministub export openapi {path} [--format yaml|json] [--out {path}]\nministub migrate {path} [--out {path}] [--write]\nministub validate [path] [-f {path}]... [--profile {name}] [--format human|json]\nministub lint [path] [-f {path}]... [--profile {name}] [--format human|json]\nministub config print [path] [-f {path}]... [--profile {name}] [--format yaml|json] [--expand]\nministub schema [--out {path}]\nministub verify [--method {method}] [--path {path}] [--header {name:value}]... [--body {field:type}]... [--exactly {n} | --at-least {n} | --at-most {n} | --never] [--url {url}]\nministub fire {request-id} --target {service} [--url {url}]\nministub fire --file {path} [--url {url}]\n")
 			os.Exit(0)
 		case data == "-p":
-			port, err = strconv.Atoi(os.Args[i+1])
+			if opts.port, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("Invalid Port %s", value)
+			}
 		case data == "-b":
-			bind = os.Args[i+1]
+			opts.bind = value
+		case data == "-w":
+			seconds, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid Watch Interval %s", value)
+			}
+			opts.watch = time.Duration(seconds) * time.Second
+		case data == "-k":
+			opts.keepStats = true
+		case data == "-f":
+			files = append(files, value)
+		case data == "--profile":
+			profile = value
 		default:
-			if i > 0 && os.Args[i-1] != "-p" && os.Args[i-1] != "-b" {
+			if i > 0 && !valueFlags[os.Args[i-1]] {
 				cfgPath = os.Args[i]
 			}
 		}
 	}
 
-	if port == 0 {
-		port = 8080
+	if opts.port == 0 {
+		opts.port = 8080
 	}
-	if len(bind) == 0 {
-		bind = "0.0.0.0"
+	if len(opts.bind) == 0 {
+		opts.bind = "0.0.0.0"
 	}
-	if len(cfgPath) == 0 {
+	if len(cfgPath) == 0 && len(files) == 0 {
 		if cwd, err := os.Getwd(); err == nil {
 			if string(cwd[len(cwd)-1]) != "/" {
 				cwd += "/"
 			}
 			cfgPath = fmt.Sprintf("%sministub.yml", cwd)
 		} else {
-			return "", "", -1, err
+			return nil, err
 		}
 	}
 
-	return cfgPath, bind, port, err
+	if opts.cfgPaths, err = configPaths(cfgPath, files, profile); err != nil {
+		return nil, err
+	}
+	return opts, err
 }
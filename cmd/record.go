@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/api"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+// runRecord proxies all traffic to an upstream service, writing each exchange into a ministub config
+func runRecord(log logger.Logger, args []string) {
+	flags := flag.NewFlagSet("record", flag.ExitOnError)
+	upstream := flags.String("upstream", "", "URL of the real service to record, e.g. http://localhost:9000")
+	outPath := flags.String("out", "recorded.yml", "Path to write the recorded config to")
+	maxExchanges := flags.Int("max-exchanges", 10000, "Most exchanges to record, later requests are forwarded but not recorded")
+	matchHeaders := flags.Bool("match-headers", false, "Require request headers sent identically on every call to an endpoint")
+	port := flags.Int("p", 8080, "Port")
+	bind := flags.String("b", "0.0.0.0", "Accept Host")
+	flags.Parse(args)
+
+	if len(*upstream) == 0 || *maxExchanges <= 0 {
+		fmt.Fprintf(os.Stderr, "Usage: ministub record --upstream {url} --out {path}\n")
+		flags.PrintDefaults()
+		os.Exit(1)
+	}
+
+	recorder, err := api.NewRecorder(log, *upstream, *outPath, *maxExchanges, *matchHeaders)
+	if err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Start Recording: %s", err.Error()))
+	}
+
+	// the recording is written once more on shutdown so the last exchanges are not lost
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-shutdown
+		if err := recorder.Flush(); err != nil {
+			logFatal(log, fmt.Sprintf("Unable To Write Recording: %s", err.Error()))
+		}
+		log.Info(fmt.Sprintf("Recording Written To %s", *outPath))
+		os.Exit(0)
+	}()
+
+	logFatal(log, fmt.Sprintf("Fatal Error: %s", recorder.ListenAndServe(*bind, *port).Error()))
+}
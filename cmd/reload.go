@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/api"
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+/*watchConfig reloads the config on SIGHUP, and whenever the files it was loaded from change when interval is above zero.
+A config which fails to load or validate is logged and the active config is kept, listeners are only changed by a restart */
+func watchConfig(log logger.Logger, paths []string, cfg *config.Config, server *api.HTTPAPI, interval time.Duration, keepStats bool) {
+	watcher := config.NewWatcher(watchedFiles(paths, cfg))
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-hangup:
+			log.Info("SIGHUP Received, Reloading Config...")
+		case <-tick:
+			if !watcher.Changed() {
+				continue
+			}
+			log.Info("Config Changed, Reloading...")
+		}
+
+		next, err := loadConfig(paths)
+		if err != nil {
+			logConfigErrors(log, fmt.Sprintf("Unable To Reload From Path %s, Keeping Active Config", strings.Join(paths, ", ")), err)
+			continue
+		}
+		if err = config.Validate(next); err != nil {
+			logConfigErrors(log, "Config Validation Error, Keeping Active Config", err)
+			continue
+		}
+		if err = server.Reload(next, keepStats); err != nil {
+			log.Error(fmt.Sprintf("Unable To Reload Config, Keeping Active Config: %s", err.Error()))
+			continue
+		}
+
+		// includes may have changed, so watch the files the new config was loaded from
+		watcher.Watch(watchedFiles(paths, next))
+		log.Info(fmt.Sprintf("Config Reloaded From Path: %s", strings.Join(paths, ", ")))
+		if !reflect.DeepEqual(cfg.Listeners, next.Listeners) {
+			log.Info("Listeners Changed, Changes Take Effect On Restart")
+		}
+		cfg = next
+	}
+}
+
+// watchedFiles returns the files to watch for the given config, a config converted from an OpenAPI spec is only the spec itself
+func watchedFiles(paths []string, cfg *config.Config) []string {
+	if files := cfg.Files(); len(files) > 0 {
+		return files
+	}
+	return paths
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+// schemaUsage is printed when the schema command is given invalid args
+const schemaUsage = "Usage:\nministub schema [--out {path}]\n"
+
+// runSchema prints the JSON Schema of the config file
+func runSchema(log logger.Logger, args []string) {
+	flags := flag.NewFlagSet("schema", flag.ExitOnError)
+	outPath := flags.String("out", "", "Path to write the schema to, defaults to stdout")
+	if path := parseFlagsWithOptionalPath(flags, args, schemaUsage); len(path) > 0 {
+		fmt.Fprint(os.Stderr, schemaUsage)
+		os.Exit(1)
+	}
+
+	data, err := json.MarshalIndent(config.Schema(), "", "  ")
+	if err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Marshal Schema: %s", err.Error()))
+	}
+	writeOutput(log, append(data, '\n'), *outPath)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+// validateUsage is printed when the validate command is given invalid args
+const validateUsage = "Usage:\nministub validate [path] [-f {path}]... [--profile {name}] [--format human|json]\n"
+
+// runValidate loads and validates a config, printing every error found and exiting non-zero if there are any
+func runValidate(log logger.Logger, args []string) {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	overlays := addOverlayFlags(flags)
+	format := flags.String("format", "human", "Output format, human or json")
+	paths, err := overlays.paths(parseFlagsWithOptionalPath(flags, args, validateUsage))
+	if err != nil {
+		fmt.Fprint(os.Stderr, validateUsage)
+		os.Exit(1)
+	}
+	inPath := paths[0]
+
+	if *format != "human" && *format != "json" {
+		logFatal(log, fmt.Sprintf("Unknown Format: %s", *format))
+	}
+
+	_, errs := loadAndValidate(paths)
+	reportErrors(errs, *format, fmt.Sprintf("%s: Config Valid", inPath), "Error")
+}
+
+// loadAndValidate loads and validates the config at the given paths, any error is returned as ValidationErrors
+func loadAndValidate(paths []string) (*config.Config, config.ValidationErrors) {
+	cfg, err := config.LoadFromFiles(paths)
+	if err == nil {
+		err = config.Validate(cfg)
+	}
+
+	errs, valid := err.(config.ValidationErrors)
+	if err != nil && !valid {
+		errs = config.ValidationErrors{{File: paths[0], Msg: err.Error()}}
+	}
+	return cfg, errs
+}
+
+// reportErrors prints the given errors in the given format then exits non-zero if there are any
+func reportErrors(errs config.ValidationErrors, format, success, noun string) {
+	switch {
+	case format == "json":
+		if errs == nil {
+			errs = config.ValidationErrors{}
+		}
+		data, _ := json.MarshalIndent(errs, "", "  ")
+		fmt.Println(string(data))
+	case len(errs) == 0:
+		fmt.Println(success)
+	default:
+		for _, entry := range errs {
+			fmt.Println(entry.Error())
+		}
+		if len(errs) == 1 {
+			fmt.Printf("1 %s Found\n", noun)
+		} else {
+			fmt.Printf("%d %ss Found\n", len(errs), noun)
+		}
+	}
+
+	if len(errs) > 0 {
+		os.Exit(1)
+	}
+}
+
+// logConfigErrors logs each error found loading or validating a config on its own line
+func logConfigErrors(log logger.Logger, msg string, err error) {
+	errs, valid := err.(config.ValidationErrors)
+	if !valid {
+		log.Error(fmt.Sprintf("%s: %s", msg, err.Error()))
+		return
+	}
+
+	for _, entry := range errs {
+		log.Error(fmt.Sprintf("%s: %s", msg, entry.Error()))
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/api"
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// verifyUsage is printed when the verify command is given invalid args
+const verifyUsage = "Usage:\nministub verify [--method {method}] [--path {path}] [--endpoint {url}] [--since {time}] [--header {name:value}]... [--body {field:type}]...\n\t[--exactly {n} | --at-least {n} | --at-most {n} | --never] [--file {path}] [--url {url}] [--prefix {path}] [--token {token}] [--timeout {duration}] [--format human|json]\n"
+
+// runVerify asserts a running server received the requests described, exiting non-zero if it did not
+func runVerify(log logger.Logger, args []string) {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	admin := addAdminFlags(flags, 30*time.Second)
+	verification := new(api.Verification)
+	var headers, body pathList
+	var exactly, atLeast, atMost int
+	flags.StringVar(&verification.Method, "method", "", "Method of the requests")
+	flags.StringVar(&verification.Path, "path", "", "Path of the requests, ':name' segments match any value")
+	flags.StringVar(&verification.Endpoint, "endpoint", "", "URL of the endpoint which matched the requests")
+	flags.StringVar(&verification.Since, "since", "", "RFC 3339 time, earlier requests are ignored")
+	flags.Var(&headers, "header", "Header the requests must have as name:value, may be repeated")
+	flags.Var(&body, "body", "JSON body field the requests must have as field:type, may be repeated")
+	flags.IntVar(&exactly, "exactly", 0, "Number of times the requests must have been received")
+	flags.IntVar(&atLeast, "at-least", 0, "Minimum number of times the requests must have been received")
+	flags.IntVar(&atMost, "at-most", 0, "Maximum number of times the requests may have been received")
+	flags.BoolVar(&verification.Never, "never", false, "The requests must never have been received")
+	file := flags.String("file", "", "YAML or JSON file holding the verification, in place of the flags")
+	format := flags.String("format", "human", "Output format, human or json")
+	if len(parseFlagsWithOptionalPath(flags, args, verifyUsage)) > 0 {
+		fmt.Fprint(os.Stderr, verifyUsage)
+		os.Exit(1)
+	}
+
+	if *format != "human" && *format != "json" {
+		logFatal(log, fmt.Sprintf("Unknown Format: %s", *format))
+	}
+
+	// counts are only set when given, as zero is a valid count
+	flags.Visit(func(f *flag.Flag) {
+		switch {
+		case f.Name == "exactly":
+			verification.Exactly = &exactly
+		case f.Name == "at-least":
+			verification.AtLeast = &atLeast
+		case f.Name == "at-most":
+			verification.AtMost = &atMost
+		}
+	})
+
+	if len(headers) > 0 || len(body) > 0 {
+		verification.Recieves = &config.Recieves{Headers: make(map[string]string), Body: make(map[string]string)}
+		for _, entry := range headers {
+			i := strings.Index(entry, ":")
+			if i <= 0 {
+				logFatal(log, fmt.Sprintf("Header %s Is Not name:value", entry))
+			}
+			verification.Recieves.Headers[entry[:i]] = strings.TrimSpace(entry[i+1:])
+		}
+		for _, entry := range body {
+			i := strings.LastIndex(entry, ":")
+			if i <= 0 {
+				logFatal(log, fmt.Sprintf("Body Field %s Is Not field:type", entry))
+			}
+			verification.Recieves.Body[entry[:i]] = entry[i+1:]
+		}
+	}
+
+	content, err := yaml.Marshal(verification)
+	if len(*file) > 0 {
+		content, err = ioutil.ReadFile(*file)
+	}
+	if err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Read Verification: %s", err.Error()))
+	}
+
+	statusCode, data, err := admin.request(http.MethodPost, "/verify", content)
+	if err != nil {
+		logFatal(log, err.Error())
+	}
+	if statusCode != http.StatusOK && statusCode != http.StatusExpectationFailed {
+		logFatal(log, fmt.Sprintf("Verification Returned %d: %s", statusCode, strings.TrimSpace(string(data))))
+	}
+
+	result := new(api.VerifyResult)
+	if err := json.Unmarshal(data, result); err != nil {
+		logFatal(log, fmt.Sprintf("Unable To Decode Verification Result: %s", err.Error()))
+	}
+
+	if *format == "json" {
+		output, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(output))
+	} else {
+		printVerifyResult(result)
+	}
+
+	if !result.Pass {
+		os.Exit(1)
+	}
+}
+
+// printVerifyResult prints the outcome of a verification, with each near miss and the reasons it did not match
+func printVerifyResult(result *api.VerifyResult) {
+	if result.Pass {
+		fmt.Printf("Verified: Received %d Matching Requests, Expected %s\n", result.Count, result.Expected)
+		return
+	}
+
+	fmt.Printf("Verification Failed: Received %d Matching Requests, Expected %s\n", result.Count, result.Expected)
+	for _, entry := range result.Matched {
+		fmt.Printf("Matched: %s %s At %s\n", entry.Method, entry.Path, entry.ReceivedAt.Format("15:04:05.000"))
+	}
+	for _, miss := range result.NearMisses {
+		fmt.Printf("Near Miss: %s %s At %s: %s\n", miss.Request.Method, miss.Request.Path, miss.Request.ReceivedAt.Format("15:04:05.000"), strings.Join(miss.Mismatches, ", "))
+	}
+}
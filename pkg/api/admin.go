@@ -0,0 +1,139 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// adminVersion is the version segment every admin route is served under
+const adminVersion = "/v1"
+
+// publicAdminRoutes are served without the admin token, so orchestrators can probe them without credentials
+var publicAdminRoutes = map[string]bool{adminVersion + "/health": true, adminVersion + "/ready": true}
+
+// adminHandlerFunc serves a single admin route, returning the status code written
+type adminHandlerFunc func(w http.ResponseWriter, r *http.Request) int
+
+// adminRoutes returns the handlers of the admin API, route -> method : handler
+func (api *HTTPAPI) adminRoutes() map[string]map[string]adminHandlerFunc {
+	return map[string]map[string]adminHandlerFunc{
+		"/stats":           {http.MethodGet: api.statsHandler, http.MethodDelete: api.resetStatsHandler},
+		"/stats/listeners": {http.MethodGet: api.listenerStatsHandler},
+		"/stats/proxy":     {http.MethodGet: api.proxyStatsHandler},
+		"/exit":            {http.MethodPost: api.exitHandler},
+		"/health":          {http.MethodGet: api.healthHandler},
+		"/ready":           {http.MethodGet: api.readyHandler},
+		"/metrics":         {http.MethodGet: api.metricsHandler},
+		"/requests":        {http.MethodGet: api.requestsHandler, http.MethodDelete: api.clearRequestsHandler},
+		"/requests/wait":   {http.MethodGet: api.waitRequestsHandler},
+		"/verify":          {http.MethodPost: api.verifyHandler},
+		"/fire":            {http.MethodPost: api.fireHandler},
+		"/endpoints": {
+			http.MethodGet:    api.endpointsHandler,
+			http.MethodPost:   api.createEndpointsHandler,
+			http.MethodPut:    api.replaceEndpointsHandler,
+			http.MethodDelete: api.deleteEndpointsHandler,
+		},
+	}
+}
+
+/*adminHandler serves every request under the admin prefix, each route is versioned so '/stats' is served from
+'{prefix}/v1/stats'. When a token is configured it must be given as a bearer token in the Authorization header, by every
+route other than the health and readiness probes */
+func (api *HTTPAPI) adminHandler(w http.ResponseWriter, r *http.Request) {
+	cfg, _ := api.current()
+	statusCode := api.serveAdmin(cfg.Admin, w, r)
+	api.log.Info(fmt.Sprintf("%s | %s | %d", r.Host, r.URL.Path, statusCode))
+}
+
+// serveAdmin authorises an admin request then passes it to the handler of its route, returning the status code written
+func (api *HTTPAPI) serveAdmin(admin *config.Admin, w http.ResponseWriter, r *http.Request) int {
+	if !admin.Matches(r.URL.Path) {
+		return api.adminError(w, &HTTPError{"URL Not Found", http.StatusNotFound})
+	}
+	route := strings.TrimPrefix(r.URL.Path, admin.PathPrefix())
+	if !publicAdminRoutes[route] && !authorised(admin, r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		return api.adminError(w, &HTTPError{"Invalid Or Missing Admin Token", http.StatusUnauthorized})
+	}
+
+	if !strings.HasPrefix(route, adminVersion+"/") {
+		return api.adminError(w, &HTTPError{"Unsupported Admin API Version", http.StatusNotFound})
+	}
+
+	methods, found := api.adminRoutes()[strings.TrimPrefix(route, adminVersion)]
+	if !found {
+		return api.adminError(w, &HTTPError{"URL Not Found", http.StatusNotFound})
+	}
+	handler, found := methods[r.Method]
+	if !found {
+		return api.adminError(w, &HTTPError{"Method For URL Not Found", http.StatusMethodNotAllowed})
+	}
+	return handler(w, r)
+}
+
+// authorised checks the request holds the configured admin token, every request is authorised when there is none
+func authorised(admin *config.Admin, r *http.Request) bool {
+	if admin == nil || len(admin.Token) == 0 {
+		return true
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(admin.Token)) == 1
+}
+
+// adminError writes the given error as JSON, returning its status code
+func (api *HTTPAPI) adminError(w http.ResponseWriter, err *HTTPError) int {
+	api.setupErrorResponse(err, w)
+	return err.StatusCode()
+}
+
+// writeJSON writes the given value as a JSON response, returning the status code written
+func (api *HTTPAPI) writeJSON(w http.ResponseWriter, statusCode int, value interface{}) int {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return api.adminError(w, &HTTPError{fmt.Sprintf("Unable To Write Response Body: %s", err.Error()), http.StatusInternalServerError})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(data)
+	return statusCode
+}
+
+// listenerStatsHandler returns the per-pattern match counts of every raw TCP/UDP listener as JSON
+func (api *HTTPAPI) listenerStatsHandler(w http.ResponseWriter, r *http.Request) int {
+	stats := make(map[string]map[string]int, len(api.listeners))
+	for _, listener := range api.listeners {
+		stats[listener.Name()] = listener.Stats()
+	}
+	return api.writeJSON(w, http.StatusOK, stats)
+}
+
+// proxyStatsHandler returns the status code counts of every request forwarded to an upstream as JSON
+func (api *HTTPAPI) proxyStatsHandler(w http.ResponseWriter, r *http.Request) int {
+	stats := make(map[string]map[int]int)
+	if _, proxy := api.current(); proxy != nil {
+		stats = proxy.Stats()
+	}
+	return api.writeJSON(w, http.StatusOK, stats)
+}
+
+// exitHandler responds then quits the application
+func (api *HTTPAPI) exitHandler(w http.ResponseWriter, r *http.Request) int {
+	api.log.Info("Exit Requested, Shutting Down...")
+	api.writeJSON(w, http.StatusOK, map[string]string{"status": "Shutting Down"})
+	if flusher, valid := w.(http.Flusher); valid {
+		flusher.Flush()
+	}
+	osExit(0)
+	return http.StatusOK
+}
+
+// osExit quits the application, saved to a variable to allow mocking
+var osExit = os.Exit
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// endpointsHandler returns every endpoint being served as JSON, keyed by URL then method as in the config
+func (api *HTTPAPI) endpointsHandler(w http.ResponseWriter, r *http.Request) int {
+	cfg, _ := api.current()
+	return api.writeJSON(w, http.StatusOK, config.ToJSON(cfg.Endpoints))
+}
+
+// createEndpointsHandler adds the endpoints in the request body, rejecting them all if any URL and method is already served
+func (api *HTTPAPI) createEndpointsHandler(w http.ResponseWriter, r *http.Request) int {
+	return api.updateEndpoints(w, r, false)
+}
+
+// replaceEndpointsHandler adds the endpoints in the request body, replacing any already served at the same URL and method
+func (api *HTTPAPI) replaceEndpointsHandler(w http.ResponseWriter, r *http.Request) int {
+	return api.updateEndpoints(w, r, true)
+}
+
+/*updateEndpoints validates the endpoints in the request body then serves them in place of the active config, which is
+copied rather than modified so requests in-flight are unaffected. Either every endpoint is added or none are */
+func (api *HTTPAPI) updateEndpoints(w http.ResponseWriter, r *http.Request, replace bool) int {
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return api.adminError(w, &HTTPError{"Error Reading Incoming Body", http.StatusInternalServerError})
+	}
+	added, err := config.ParseEndpoints(content)
+	if err != nil {
+		return api.validationError(w, "Invalid Endpoints", err)
+	}
+	if len(added) == 0 {
+		return api.adminError(w, &HTTPError{"No Endpoints Given", http.StatusBadRequest})
+	}
+
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	errs := make(config.ValidationErrors, 0)
+	endpoints := copyEndpoints(api.cfg.Endpoints)
+	for url, methods := range added {
+		for method, entry := range methods {
+			method = strings.ToLower(method)
+			if _, found := endpoints[url][method]; found && !replace {
+				return api.adminError(w, &HTTPError{fmt.Sprintf("Endpoint %s %s Already Exists", strings.ToUpper(method), url), http.StatusConflict})
+			}
+			if err := config.ValidateEndpoint(api.cfg, url, method, entry); err != nil {
+				found, _ := err.(config.ValidationErrors)
+				errs = append(errs, found...)
+				continue
+			}
+
+			if endpoints[url] == nil {
+				endpoints[url] = make(map[string]*config.Endpoint)
+			}
+			endpoints[url][method] = entry
+		}
+	}
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+		return api.validationError(w, "Invalid Endpoints", errs)
+	}
+
+	api.cfg = api.cfg.WithEndpoints(endpoints)
+	// a replaced endpoint starts its stats again, as it may return different status codes
+	api.stats.Retain(func(url string) bool {
+		_, found := added[strings.Split(url, "#")[0]]
+		return !found
+	})
+
+	if replace {
+		return api.writeJSON(w, http.StatusOK, config.ToJSON(added))
+	}
+	return api.writeJSON(w, http.StatusCreated, config.ToJSON(added))
+}
+
+/*deleteEndpointsHandler removes the endpoint with the 'url' and 'method' query params, or every method of the URL when no
+method is given. Endpoints loaded from the config may be removed as well as those added at runtime */
+func (api *HTTPAPI) deleteEndpointsHandler(w http.ResponseWriter, r *http.Request) int {
+	url, method := r.URL.Query().Get("url"), strings.ToLower(r.URL.Query().Get("method"))
+	if len(url) == 0 {
+		return api.adminError(w, &HTTPError{"Missing Query Parameter: url", http.StatusBadRequest})
+	}
+
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	endpoints := copyEndpoints(api.cfg.Endpoints)
+	if _, found := endpoints[url][method]; len(method) > 0 && !found {
+		return api.adminError(w, &HTTPError{fmt.Sprintf("Endpoint %s %s Not Found", strings.ToUpper(method), url), http.StatusNotFound})
+	}
+	if _, found := endpoints[url]; !found {
+		return api.adminError(w, &HTTPError{fmt.Sprintf("Endpoint %s Not Found", url), http.StatusNotFound})
+	}
+
+	if len(method) > 0 {
+		delete(endpoints[url], method)
+	}
+	if len(method) == 0 || len(endpoints[url]) == 0 {
+		delete(endpoints, url)
+	}
+
+	api.cfg = api.cfg.WithEndpoints(endpoints)
+	api.stats.Retain(func(url string) bool { return endpointExists(api.cfg, url) })
+
+	w.WriteHeader(http.StatusNoContent)
+	return http.StatusNoContent
+}
+
+// validationError writes the given config errors as JSON, each with its path and message
+func (api *HTTPAPI) validationError(w http.ResponseWriter, msg string, err error) int {
+	errs, valid := err.(config.ValidationErrors)
+	if !valid {
+		errs = config.ValidationErrors{{Msg: err.Error()}}
+	}
+	return api.writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": msg, "errors": errs})
+}
+
+// copyEndpoints copies the URL and method maps of the given endpoints, the endpoints themselves are shared
+func copyEndpoints(endpoints map[string]map[string]*config.Endpoint) map[string]map[string]*config.Endpoint {
+	result := make(map[string]map[string]*config.Endpoint, len(endpoints))
+	for url, methods := range endpoints {
+		result[url] = make(map[string]*config.Endpoint, len(methods))
+		for method, entry := range methods {
+			result[url][method] = entry
+		}
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+// testHTTPAPI returns an API serving a single endpoint at '/stats'
+func testHTTPAPI(admin *config.Admin) *HTTPAPI {
+	cfg := &config.Config{
+		Version:   2.0,
+		Endpoints: map[string]map[string]*config.Endpoint{"/stats": {"get": {Response: http.StatusTeapot}}},
+		Admin:     admin,
+	}
+	return NewHTTPAPI(logger.NewLogger("std"), cfg, nil)
+}
+
+// TestAdminHandler1 ensures the admin API is served under its prefix without hiding an endpoint at '/stats'
+func TestAdminHandler1(t *testing.T) {
+	api := testHTTPAPI(nil)
+
+	for path, statusCode := range map[string]int{
+		"/stats":                   http.StatusTeapot,
+		"/__admin/v1/stats":        http.StatusOK,
+		"/__admin/v1/stats/proxy":  http.StatusOK,
+		"/__admin/v2/stats":        http.StatusNotFound,
+		"/__admin/v1/missing":      http.StatusNotFound,
+		"/__admin/v1/exit":         http.StatusMethodNotAllowed,
+		"/__admin/v1/stats/listen": http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != statusCode {
+			t.Errorf("Unexpected Status Code For %s: %d", path, w.Code)
+		}
+	}
+}
+
+// TestAdminHandler2 ensures admin requests require the configured bearer token
+func TestAdminHandler2(t *testing.T) {
+	api := testHTTPAPI(&config.Admin{Prefix: "/_internal", Token: "secret"})
+
+	for token, statusCode := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"Bearer secret": http.StatusOK,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/_internal/v1/stats", nil)
+		if len(token) > 0 {
+			r.Header.Set("Authorization", token)
+		}
+		w := httptest.NewRecorder()
+		api.mux.ServeHTTP(w, r)
+		if w.Code != statusCode {
+			t.Errorf("Unexpected Status Code For Token '%s': %d", token, w.Code)
+		}
+		if statusCode == http.StatusOK && w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Admin Response Is Not JSON: %s", w.Header().Get("Content-Type"))
+		}
+	}
+}
+
+// TestEndpointsHandler1 ensures endpoints created, replaced and deleted at runtime take effect on the next request
+func TestEndpointsHandler1(t *testing.T) {
+	api := testHTTPAPI(nil)
+	original, _ := api.current()
+
+	for _, step := range []struct {
+		method, path, body string
+		statusCode         int
+	}{
+		{http.MethodPost, "/__admin/v1/endpoints", `{"/a": {"get": {"response": 201}}}`, http.StatusCreated},
+		{http.MethodGet, "/a", "", http.StatusCreated},
+		{http.MethodPost, "/__admin/v1/endpoints", `{"/a": {"get": {"response": 202}}}`, http.StatusConflict},
+		{http.MethodPut, "/__admin/v1/endpoints", `{"/a": {"get": {"response": 202}}}`, http.StatusOK},
+		{http.MethodGet, "/a", "", http.StatusAccepted},
+		{http.MethodPut, "/__admin/v1/endpoints", `{"/a": {"get": {"responses": {"200": {"weight": 50}}}}}`, http.StatusBadRequest},
+		{http.MethodGet, "/a", "", http.StatusAccepted},
+		{http.MethodDelete, "/__admin/v1/endpoints?url=/a&method=get", "", http.StatusNoContent},
+		{http.MethodGet, "/a", "", http.StatusNotFound},
+		{http.MethodDelete, "/__admin/v1/endpoints?url=/a", "", http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		api.mux.ServeHTTP(w, httptest.NewRequest(step.method, step.path, strings.NewReader(step.body)))
+		if w.Code != step.statusCode {
+			t.Errorf("Unexpected Status Code For %s %s: %d, %s", step.method, step.path, w.Code, w.Body.String())
+		}
+	}
+
+	if len(original.Endpoints) != 1 {
+		t.Errorf("Original Config Modified: %v", original.Endpoints)
+	}
+}
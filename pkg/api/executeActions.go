@@ -8,41 +8,57 @@ import (
 	"github.com/MichaelWittgreffe/ministub/pkg/logger"
 )
 
-// ExecuteActions runs any actions requested, performs own logging and is designed to be run in its own goroutine
-func ExecuteActions(actions []map[string]interface{}, caller string, cfg *config.Config, log logger.Logger, req Requester) {
-	for _, actionEntry := range actions {
-		for name, action := range actionEntry {
-			switch {
-			case name == "delay":
-				if period, valid := action.(int); valid {
-					log.Info(fmt.Sprintf("%s: Delay Requested For %d Seconds", caller, period))
-					time.Sleep(time.Duration(period) * time.Second)
-				}
-			case name == "request":
-				var target *config.Service
-				var request *config.Request
-
-				if actionEntry, valid := action.(map[interface{}]interface{}); valid {
-					for k, v := range actionEntry {
-						switch {
-						case k.(string) == "target":
-							target = cfg.Services[v.(string)]
-						case k.(string) == "id":
-							request = cfg.Requests[v.(string)]
-						}
-					}
-				}
+// ActionResult is the outcome of a single action, a request action fails if it cannot be sent or its response is not as expected
+type ActionResult struct {
+	Action  string `json:"action"`
+	Delay   int    `json:"delay,omitempty"`   // seconds delayed
+	Request string `json:"request,omitempty"` // ID of the request sent
+	Target  string `json:"target,omitempty"`  // service the request was sent to
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+
+	sent     bool          // set once a request is sent, whether or not it succeeds
+	duration time.Duration // time taken to send a request and receive its response
+}
+
+/*ExecuteActions runs any actions requested, performs own logging and is designed to be run in its own goroutine. The outcome
+of each action is returned in order, for callers which wait for the actions to complete */
+func ExecuteActions(actions []*config.Action, caller string, cfg *config.Config, log logger.Logger, req Requester) []*ActionResult {
+	results := make([]*ActionResult, 0, len(actions))
+	for _, action := range actions {
+		if action == nil {
+			continue
+		}
+
+		if action.Delay != nil {
+			log.Info(fmt.Sprintf("%s: Delay Requested For %d Seconds", caller, action.Delay.Seconds))
+			time.Sleep(time.Duration(action.Delay.Seconds) * time.Second)
+			results = append(results, &ActionResult{Action: "delay", Delay: action.Delay.Seconds, Success: true})
+		}
+
+		if action.Request != nil {
+			result := &ActionResult{Action: "request", Request: action.Request.ID, Target: action.Request.Target}
+			target := cfg.Services[action.Request.Target]
+			request := cfg.Requests[action.Request.ID]
+
+			if target != nil && request != nil {
+				start := time.Now()
+				err := req.Request(target, request)
+				result.sent, result.duration = true, time.Since(start)
 
-				if target != nil && request != nil {
-					if err := req.Request(target, request); err == nil {
-						log.Info(fmt.Sprintf("Request %s To Service %s:%d Succesful", request.URL, target.Hostname, target.Port))
-					} else {
-						log.Error(fmt.Sprintf("Request %s To Service %s:%d Failed: %s", request.URL, target.Hostname, target.Port, err.Error()))
-					}
+				if err == nil {
+					result.Success = true
+					log.Info(fmt.Sprintf("Request %s To Service %s:%d Succesful", request.URL, target.Hostname, target.Port))
 				} else {
-					log.Error("Invalid Request Requested")
+					result.Error = err.Error()
+					log.Error(fmt.Sprintf("Request %s To Service %s:%d Failed: %s", request.URL, target.Hostname, target.Port, err.Error()))
 				}
+			} else {
+				result.Error = "Invalid Request Requested"
+				log.Error("Invalid Request Requested")
 			}
+			results = append(results, result)
 		}
 	}
+	return results
 }
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+// Fire describes actions run on demand, either a single request sent to a service or a list of actions
+type Fire struct {
+	Request string           `yaml:"request,omitempty" json:"request,omitempty"` // ID of a request in the 'requests' section
+	Target  string           `yaml:"target,omitempty" json:"target,omitempty"`   // service the request is sent to
+	Actions []*config.Action `yaml:"actions,omitempty" json:"actions,omitempty"`
+}
+
+// FireResult is the outcome of every action fired, which succeed only if every request is sent and its response is as expected
+type FireResult struct {
+	Success bool            `json:"success"`
+	Results []*ActionResult `json:"results"`
+}
+
+// actions returns the actions to fire, a single request is fired as a list of one request action
+func (f *Fire) actions() ([]*config.Action, error) {
+	switch {
+	case len(f.Actions) > 0 && (len(f.Request) > 0 || len(f.Target) > 0):
+		return nil, fmt.Errorf("Only One Of request Or actions May Be Set")
+	case len(f.Actions) > 0:
+		return f.Actions, nil
+	case len(f.Request) > 0 || len(f.Target) > 0:
+		return []*config.Action{{Request: &config.RequestAction{Target: f.Target, ID: f.Request}}}, nil
+	default:
+		return nil, fmt.Errorf("One Of request Or actions Must Be Set")
+	}
+}
+
+/*fireHandler runs the request or actions in the request body immediately, responding once they complete with the outcome of
+each. Actions are validated against the active config before any are run */
+func (api *HTTPAPI) fireHandler(w http.ResponseWriter, r *http.Request) int {
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return api.adminError(w, &HTTPError{"Error Reading Incoming Body", http.StatusInternalServerError})
+	}
+
+	fire := new(Fire)
+	if err := yaml.UnmarshalStrict(content, fire); err != nil {
+		return api.adminError(w, &HTTPError{fmt.Sprintf("Invalid Actions: %s", err.Error()), http.StatusBadRequest})
+	}
+	actions, err := fire.actions()
+	if err != nil {
+		return api.adminError(w, &HTTPError{fmt.Sprintf("Invalid Actions: %s", err.Error()), http.StatusBadRequest})
+	}
+
+	cfg, _ := api.current()
+	if err := config.ValidateActions(cfg, actions); err != nil {
+		return api.validationError(w, "Invalid Actions", err)
+	}
+
+	result := &FireResult{Success: true, Results: api.RunActions(actions, "Admin", cfg)}
+	for _, entry := range result.Results {
+		result.Success = result.Success && entry.Success
+	}
+	return api.writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+// mockRequester records the URL of each request made, failing any to '/bad'
+type mockRequester struct {
+	sent []string
+}
+
+// Request records the request, returning an error if its URL is '/bad'
+func (m *mockRequester) Request(tgt *config.Service, req *config.Request) error {
+	m.sent = append(m.sent, req.URL)
+	if req.URL == "/bad" {
+		return fmt.Errorf("Status Code MisMatch")
+	}
+	return nil
+}
+
+// TestFireHandler1 ensures requests are only fired when valid, and the outcome of each is returned
+func TestFireHandler1(t *testing.T) {
+	cfg := &config.Config{
+		Version:  2.0,
+		Services: map[string]*config.Service{"api": {Hostname: "localhost", Port: 8080}},
+		Requests: map[string]*config.Request{"ping": {URL: "/ping", Method: "get"}, "bad": {URL: "/bad", Method: "get"}},
+	}
+	requester := new(mockRequester)
+	api := NewHTTPAPI(logger.NewLogger("std"), cfg, requester)
+
+	for _, entry := range []struct {
+		body       string
+		statusCode int
+		success    bool
+	}{
+		{`{"request": "ping", "target": "api"}`, http.StatusOK, true},
+		{`{"actions": [{"delay": 0}, {"request": {"id": "ping", "target": "api"}}, {"request": {"id": "bad", "target": "api"}}]}`, http.StatusOK, false},
+		{`{"request": "missing", "target": "api"}`, http.StatusBadRequest, false},
+		{`{"request": "ping", "target": "missing"}`, http.StatusBadRequest, false},
+		{`{"request": "ping", "target": "api", "actions": [{"delay": 0}]}`, http.StatusBadRequest, false},
+		{`{}`, http.StatusBadRequest, false},
+	} {
+		w := httptest.NewRecorder()
+		api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/__admin/v1/fire", strings.NewReader(entry.body)))
+		if w.Code != entry.statusCode {
+			t.Errorf("Unexpected Status Code For %s: %d", entry.body, w.Code)
+			continue
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+
+		result := new(FireResult)
+		if err := json.Unmarshal(w.Body.Bytes(), result); err != nil {
+			t.Fatalf("Unable To Decode Result: %s", err.Error())
+		}
+		if result.Success != entry.success {
+			t.Errorf("Unexpected Success For %s: %t", entry.body, result.Success)
+		}
+	}
+
+	if strings.Join(requester.sent, ",") != "/ping,/ping,/bad" {
+		t.Errorf("Unexpected Requests Sent: %v", requester.sent)
+	}
+}
+
+// TestFireHandler2 ensures a request with no expected response is fired and accepts any response
+func TestFireHandler2(t *testing.T) {
+	upstream := testUpstream("api", http.StatusAccepted)
+	defer upstream.Close()
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(upstream.URL, "http://"))
+	portNumber, _ := strconv.Atoi(port)
+
+	cfg := &config.Config{
+		Version:  2.0,
+		Services: map[string]*config.Service{"api": {Hostname: host, Port: portNumber}},
+		Requests: map[string]*config.Request{"ping": {URL: "/ping", Method: "get", Protocol: "http"}},
+	}
+	api := NewHTTPAPI(logger.NewLogger("std"), cfg, NewHTTPRequester())
+
+	w := httptest.NewRecorder()
+	api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/__admin/v1/fire", strings.NewReader(`{"request": "ping", "target": "api"}`)))
+
+	result := new(FireResult)
+	if err := json.Unmarshal(w.Body.Bytes(), result); w.Code != http.StatusOK || err != nil || !result.Success {
+		t.Errorf("Unexpected Result: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Readiness is the readiness of the application, listing what it is waiting for when it is not ready
+type Readiness struct {
+	Ready   bool     `json:"ready"`
+	Waiting []string `json:"waiting,omitempty"` // startupActions, warmup or unavailable
+}
+
+/*RunStartupActions runs the startup actions of the active config, then marks them finished so readiness no longer waits for
+them. It is called once at startup, even when there are no startup actions */
+func (api *HTTPAPI) RunStartupActions() {
+	cfg, _ := api.current()
+	if len(cfg.StartupActions) > 0 {
+		api.log.Info("Executing Startup Actions...")
+		api.RunActions(cfg.StartupActions, "Startup", cfg)
+	}
+
+	api.mutex.Lock()
+	api.startupFinished = true
+	api.mutex.Unlock()
+}
+
+// unavailableFor returns the time remaining in which every endpoint returns a 503, zero once the endpoints are available
+func (api *HTTPAPI) unavailableFor() time.Duration {
+	cfg, _ := api.current()
+	if remaining := cfg.Startup.UnavailablePeriod() - time.Since(api.started); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// readiness returns the readiness of the application, measured from when it started so it is not changed by a reload
+func (api *HTTPAPI) readiness() *Readiness {
+	cfg, _ := api.current()
+	api.mutex.Lock()
+	startupFinished := api.startupFinished
+	api.mutex.Unlock()
+
+	readiness := &Readiness{Ready: true}
+	if cfg.Startup.WaitsForActions() && !startupFinished {
+		readiness.Waiting = append(readiness.Waiting, "startupActions")
+	}
+	if time.Since(api.started) < cfg.Startup.WarmupPeriod() {
+		readiness.Waiting = append(readiness.Waiting, "warmup")
+	}
+	if api.unavailableFor() > 0 {
+		readiness.Waiting = append(readiness.Waiting, "unavailable")
+	}
+	readiness.Ready = len(readiness.Waiting) == 0
+	return readiness
+}
+
+// unavailableResponse rejects a request whilst the endpoints are unavailable, with a Retry-After header holding the seconds remaining
+func (api *HTTPAPI) unavailableResponse(remaining time.Duration, w http.ResponseWriter) *HTTPError {
+	seconds := int(math.Ceil(remaining.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	err := &HTTPError{fmt.Sprintf("Service Unavailable Whilst Starting, Retry In %d Seconds", seconds), http.StatusServiceUnavailable}
+	api.setupErrorResponse(err, w)
+	return err
+}
+
+// healthHandler reports the application is alive, it responds whenever the application is able to serve requests
+func (api *HTTPAPI) healthHandler(w http.ResponseWriter, r *http.Request) int {
+	return api.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyHandler reports the application is ready with a 200, or a 503 listing what it is waiting for
+func (api *HTTPAPI) readyHandler(w http.ResponseWriter, r *http.Request) int {
+	readiness := api.readiness()
+	if !readiness.Ready {
+		return api.writeJSON(w, http.StatusServiceUnavailable, readiness)
+	}
+	return api.writeJSON(w, http.StatusOK, readiness)
+}
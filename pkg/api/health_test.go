@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// TestReadyHandler1 ensures readiness waits for the startup actions, warm-up and unavailable periods
+func TestReadyHandler1(t *testing.T) {
+	api := testHTTPAPI(&config.Admin{Token: "secret"})
+	api.cfg.Startup = &config.Startup{WaitForActions: true, Warmup: 5, Unavailable: 10}
+
+	for _, step := range []struct {
+		started    time.Duration // time since starting
+		finished   bool          // startup actions finished
+		statusCode int
+		waiting    int
+	}{
+		{0, false, http.StatusServiceUnavailable, 3},
+		{6 * time.Second, true, http.StatusServiceUnavailable, 1},
+		{11 * time.Second, false, http.StatusServiceUnavailable, 1},
+		{11 * time.Second, true, http.StatusOK, 0},
+	} {
+		api.started, api.startupFinished = time.Now().Add(-step.started), step.finished
+
+		w := httptest.NewRecorder()
+		api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/__admin/v1/ready", nil))
+		readiness := new(Readiness)
+		json.Unmarshal(w.Body.Bytes(), readiness)
+		if w.Code != step.statusCode || len(readiness.Waiting) != step.waiting {
+			t.Errorf("Unexpected Readiness After %s: %d %v", step.started, w.Code, readiness.Waiting)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/__admin/v1/health", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Unexpected Health Status Code: %d", w.Code)
+	}
+}
+
+// TestServeRequest1 ensures every endpoint returns a 503 whilst unavailable, with the seconds remaining in Retry-After
+func TestServeRequest1(t *testing.T) {
+	api := testHTTPAPI(nil)
+	api.cfg.Startup = &config.Startup{Unavailable: 10}
+	api.started = time.Now().Add(-3 * time.Second)
+
+	w := httptest.NewRecorder()
+	api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "7" {
+		t.Errorf("Unexpected Response Whilst Unavailable: %d %s", w.Code, w.Header().Get("Retry-After"))
+	}
+	if rejected := api.stats.Snapshot().Rejected["startup"]; rejected != 1 {
+		t.Errorf("Unexpected Rejected Count: %d", rejected)
+	}
+
+	api.started = time.Now().Add(-11 * time.Second)
+	w = httptest.NewRecorder()
+	api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	if w.Code != http.StatusTeapot {
+		t.Errorf("Unexpected Status Code Once Available: %d", w.Code)
+	}
+}
@@ -1,13 +1,15 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
-	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/MichaelWittgreffe/ministub/pkg/config"
 	"github.com/MichaelWittgreffe/ministub/pkg/logger"
@@ -15,10 +17,20 @@ import (
 
 // HTTPAPI represents the HTTP API
 type HTTPAPI struct {
-	log   logger.Logger
-	cfg   *config.Config
-	stats map[string]map[int]int // url -> statusCode: count
-	req   Requester
+	log       logger.Logger
+	cfg       *config.Config
+	stats     *Stats
+	req       Requester
+	listeners []*SocketAPI
+	proxy     *Proxy
+	mutex     sync.Mutex     // guards cfg, proxy and startupFinished, which change whilst requests are served
+	mux       *http.ServeMux // serves the endpoints, along with the admin API when it has no port of its own
+	adminPort int            // port the admin API is served on, zero when it is served alongside the endpoints
+	journal   *Journal
+	metrics   *Metrics
+
+	started         time.Time // readiness and startup periods are measured from here
+	startupFinished bool      // set once the startup actions have finished
 }
 
 // NewHTTPAPI creates a new instance of HTTPAPI
@@ -27,50 +39,177 @@ func NewHTTPAPI(log logger.Logger, cfg *config.Config, req Requester) *HTTPAPI {
 		return nil
 	}
 	api := &HTTPAPI{
-		log:   log,
-		cfg:   cfg,
-		stats: make(map[string]map[int]int),
-		req:   req,
+		log:     log,
+		cfg:     cfg,
+		stats:   NewStats(),
+		req:     req,
+		mux:     http.NewServeMux(),
+		journal: NewJournal(cfg.Admin.JournalSize()),
+		metrics: NewMetrics(),
+		started: time.Now(),
 	}
-	http.HandleFunc("/", api.requestHandler)
+	if cfg.Proxy != nil {
+		proxy, err := NewProxy(log, cfg.Proxy)
+		if err != nil {
+			log.Error(fmt.Sprintf("Unable To Create Proxy: %s", err.Error()))
+			return nil
+		}
+		api.proxy = proxy
+	}
+	api.mux.HandleFunc("/", api.requestHandler)
 	return api
 }
 
-// ListenAndServe begins the API listening for requests
+// AddListener registers a raw TCP/UDP listener so its stats are served alongside the HTTP stats
+func (api *HTTPAPI) AddListener(listener *SocketAPI) {
+	if listener != nil {
+		listener.observe = api.observeActions
+		api.listeners = append(api.listeners, listener)
+	}
+}
+
+/*Reload swaps the active config for the given one, which must already be validated. Requests in-flight and actions already
+running continue with the config they started with. Stats are reset unless keepStats is set, in which case they are kept for
+endpoints which still exist. Endpoints changed at runtime are discarded, and listeners keep the config they were created with */
+func (api *HTTPAPI) Reload(cfg *config.Config, keepStats bool) error {
+	if cfg == nil {
+		return fmt.Errorf("Invalid Args")
+	}
+
+	var proxy *Proxy
+	if cfg.Proxy != nil {
+		var err error
+		if proxy, err = NewProxy(api.log, cfg.Proxy); err != nil {
+			return fmt.Errorf("Unable To Create Proxy: %s", err.Error())
+		}
+	}
+
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if keepStats {
+		api.stats.Retain(func(url string) bool { return endpointExists(cfg, url) })
+		if proxy != nil && api.proxy != nil {
+			proxy.stats = api.proxy.Stats()
+		}
+	} else {
+		api.stats.Reset()
+	}
+
+	api.cfg, api.proxy = cfg, proxy
+	return nil
+}
+
+// endpointExists checks the stats key of an endpoint, its URL and any SOAP operation after the first '#', is defined in the given config
+func endpointExists(cfg *config.Config, key string) bool {
+	url, operation := key, ""
+	if i := strings.Index(key, "#"); i >= 0 {
+		url, operation = key[:i], key[i+1:]
+	}
+
+	methods, found := cfg.Endpoints[url]
+	if !found {
+		return false
+	}
+	if len(operation) == 0 {
+		return true
+	}
+	for _, entry := range methods {
+		if _, found := entry.SOAP[operation]; found {
+			return true
+		}
+	}
+	return false
+}
+
+// current returns the active config and proxy, each request uses the same pair throughout even if the config is reloaded
+func (api *HTTPAPI) current() (*config.Config, *Proxy) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+	return api.cfg, api.proxy
+}
+
+/*ListenAndServe begins the API listening for requests, along with the admin API on its own port when one is configured.
+The admin port is taken from the config at startup and is not changed by a reload */
 func (api *HTTPAPI) ListenAndServe(addressBind string, port int) error {
-	api.log.Info(fmt.Sprintf("Beginning Listening For HTTP Requests On %s:%d", addressBind, port))
-	return http.ListenAndServe(fmt.Sprintf("%s:%d", addressBind, port), nil)
+	cfg, _ := api.current()
+	if cfg.Admin == nil || cfg.Admin.Port == 0 {
+		api.log.Info(fmt.Sprintf("Beginning Listening For HTTP Requests On %s:%d", addressBind, port))
+		return http.ListenAndServe(fmt.Sprintf("%s:%d", addressBind, port), api.mux)
+	}
+
+	api.mutex.Lock()
+	api.adminPort = cfg.Admin.Port
+	api.mutex.Unlock()
+
+	errs := make(chan error, 2)
+	go func() {
+		api.log.Info(fmt.Sprintf("Beginning Listening For Admin Requests On %s:%d", addressBind, cfg.Admin.Port))
+		errs <- http.ListenAndServe(fmt.Sprintf("%s:%d", addressBind, cfg.Admin.Port), http.HandlerFunc(api.adminHandler))
+	}()
+	go func() {
+		api.log.Info(fmt.Sprintf("Beginning Listening For HTTP Requests On %s:%d", addressBind, port))
+		errs <- http.ListenAndServe(fmt.Sprintf("%s:%d", addressBind, port), api.mux)
+	}()
+	return <-errs
 }
 
 // requestHandler is a handler for all incoming requests
 func (api *HTTPAPI) requestHandler(w http.ResponseWriter, r *http.Request) {
-	// check own endpoints first
-	switch {
-	case r.URL.Path == "/stats":
-		api.statsHandler(w)
-		api.log.Info(fmt.Sprintf("%s | %s | %d", r.Host, r.URL.Path, http.StatusOK))
+	cfg, proxy := api.current()
+
+	// the admin API is matched first when it shares the port of the endpoints
+	api.mutex.Lock()
+	adminPort := api.adminPort
+	api.mutex.Unlock()
+	if adminPort == 0 && cfg.Admin.Matches(r.URL.Path) {
+		api.adminHandler(w, r)
 		return
-	case r.URL.Path == "/exit":
-		api.exitHandler()
 	}
 
-	// get the entry for the incoming request
-	url, entry, err := api.getEndpointEntry(r)
-	if err != nil {
-		api.setupErrorResponse(err, w)
+	// every request to the endpoints is journalled, along with the endpoint and status code it was served with
+	record := newJournalEntry(r)
+	recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+	record.Endpoint = api.serveRequest(cfg, proxy, recorder, r)
+	record.StatusCode, record.RespondedAt = recorder.statusCode, time.Now()
+	api.journal.Record(record)
+
+	duration := record.RespondedAt.Sub(record.ReceivedAt)
+	api.metrics.ObserveRequest(record.Endpoint, r.Method, record.StatusCode, duration)
+	if len(record.Endpoint) > 0 {
+		api.stats.ObserveRequest(record.Endpoint, r.Method, record.StatusCode, duration)
+	}
+}
+
+// serveRequest serves a request to the endpoints of the given config, returning the URL of the endpoint matched
+func (api *HTTPAPI) serveRequest(cfg *config.Config, proxy *Proxy, w http.ResponseWriter, r *http.Request) string {
+	// simulate a slow start by rejecting every request until the endpoints are available
+	if remaining := api.unavailableFor(); remaining > 0 {
+		err := api.unavailableResponse(remaining, w)
 		api.log.Error(fmt.Sprintf("%s | %s | %d - %s", r.Host, r.URL.Path, err.StatusCode(), err.Error()))
-		return
+		api.observeRejected("", r.Method, "startup")
+		return ""
 	}
 
-	// get stats entry before any processing
-	stats, found := api.stats[url]
-	if !found {
-		api.addEndpointToStats(url, entry.Responses)
-		if stats, found = api.stats[url]; !found {
-			api.setupErrorResponse(&HTTPError{fmt.Sprintf("Unable To Init Stats For Endpoint: %s", url), http.StatusInternalServerError}, w)
-			api.log.Error(fmt.Sprintf("%s | %s | %d - %s", r.Host, r.URL.Path, err.StatusCode(), err.Error()))
-			return
+	// get the entry for the incoming request
+	url, entry, err := getEndpointEntry(cfg, r)
+	if err != nil {
+		unmatched := err.StatusCode() == http.StatusNotFound || err.StatusCode() == http.StatusMethodNotAllowed
+
+		// forward requests with no endpoint to the upstream when one is configured, these are counted by the proxy
+		if unmatched && proxy != nil && proxy.Forward(w, r) {
+			return url
+		}
+
+		if unmatched {
+			api.metrics.ObserveUnmatched(r.Method)
+			api.stats.ObserveUnmatched(r.Method)
+		} else {
+			api.observeRejected(url, r.Method, "path_param")
 		}
+		api.setupErrorResponse(err, w)
+		api.log.Error(fmt.Sprintf("%s | %s | %d - %s", r.Host, r.URL.Path, err.StatusCode(), err.Error()))
+		return url
 	}
 
 	// evaluate query parameters
@@ -78,103 +217,145 @@ func (api *HTTPAPI) requestHandler(w http.ResponseWriter, r *http.Request) {
 		if err = api.evaluateQueryParams(entry, r); err != nil {
 			api.setupErrorResponse(err, w)
 			api.log.Error(fmt.Sprintf("%s | %s | %d - %s", r.Host, r.URL.Path, err.StatusCode(), err.Error()))
-			return
+			api.observeRejected(url, r.Method, "query")
+			return url
 		}
 	}
 
+	data := &templateData{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Headers: r.Header}
+	setupErrorResponse := api.setupErrorResponse
+
+	// SOAP endpoints hold one definition per operation, swap to the requested operation
+	if len(entry.SOAP) > 0 {
+		setupErrorResponse = api.setupSOAPFaultResponse
+
+		if data.doc, err = readXMLBody(r); err != nil {
+			setupErrorResponse(err, w)
+			api.log.Error(fmt.Sprintf("%s | %s | %d - %s", r.Host, r.URL.Path, err.StatusCode(), err.Error()))
+			api.observeRejected(url, r.Method, "soap")
+			return url
+		}
+
+		var operation string
+		if operation, entry, err = getSOAPOperation(entry.SOAP, data.doc, r); err != nil {
+			setupErrorResponse(err, w)
+			api.log.Error(fmt.Sprintf("%s | %s | %d - %s", r.Host, r.URL.Path, err.StatusCode(), err.Error()))
+			api.observeRejected(url, r.Method, "soap_operation")
+			return url
+		}
+		url = fmt.Sprintf("%s#%s", url, operation)
+		data.Operation = operation
+	}
+
+	// list every status code of the endpoint in the stats before any processing
+	api.stats.AddEndpoint(url, r.Method, entry.Responses)
+
 	if entry.Recieves != nil {
 		// evaluate headers
 		if len(entry.Recieves.Headers) > 0 {
-			if err := api.evaluateHeaders(entry.Recieves, r); err != nil {
-				api.setupErrorResponse(err, w)
+			if err := evaluateHeaders(entry.Recieves, r.Header); err != nil {
+				setupErrorResponse(err, w)
 				api.log.Error(fmt.Sprintf("%s | %s | %d - %s", r.Host, r.URL.Path, err.StatusCode(), err.Error()))
-				return
+				api.observeRejected(url, r.Method, "header")
+				return url
 			}
 		}
 
 		// evaluate body
 		if len(entry.Recieves.Body) > 0 {
-			if err := api.evaluateBody(entry.Recieves, r); err != nil {
-				api.setupErrorResponse(err, w)
+			rawBody, err := readBody(r)
+			if err == nil {
+				err = evaluateBody(entry.Recieves, rawBody)
+			}
+			if err != nil {
+				setupErrorResponse(err, w)
+				api.log.Error(fmt.Sprintf("%s | %s | %d - %s", r.Host, r.URL.Path, err.StatusCode(), err.Error()))
+				api.observeRejected(url, r.Method, "body")
+				return url
+			}
+		}
+
+		// evaluate XML body
+		if len(entry.Recieves.XPath) > 0 {
+			if data.doc == nil {
+				if data.doc, err = readXMLBody(r); err != nil {
+					setupErrorResponse(err, w)
+					api.log.Error(fmt.Sprintf("%s | %s | %d - %s", r.Host, r.URL.Path, err.StatusCode(), err.Error()))
+					api.observeRejected(url, r.Method, "xpath")
+					return url
+				}
+			}
+			if err := evaluateXPath(entry.Recieves, data.doc); err != nil {
+				setupErrorResponse(err, w)
 				api.log.Error(fmt.Sprintf("%s | %s | %d - %s", r.Host, r.URL.Path, err.StatusCode(), err.Error()))
-				return
+				api.observeRejected(url, r.Method, "xpath")
+				return url
 			}
 		}
 	}
 
 	// setup return value
 	var statusCode int
+	var resp *config.Response
 	if entry.Response > 0 {
 		statusCode = entry.Response
 		w.WriteHeader(statusCode)
 	} else if len(entry.Responses) > 0 {
-		statusCode = api.setupResponse(url, entry.Responses, w)
+		statusCode, resp = api.setupResponse(url, entry.Responses, data, w)
 	}
 
-	// increment stats
-	stats[statusCode]++
-	api.stats[url] = stats
-
 	// start actions
 	if len(entry.Actions) > 0 {
-		go ExecuteActions(entry.Actions, r.URL.Path, api.cfg, api.log, api.req)
+		go api.RunActions(entry.Actions, r.URL.Path, cfg)
 	}
-	if entry.Responses != nil && len(entry.Responses[statusCode].Actions) > 0 {
-		go ExecuteActions(entry.Responses[statusCode].Actions, r.URL.Path, api.cfg, api.log, api.req)
+	if resp != nil && len(resp.Actions) > 0 {
+		go api.RunActions(resp.Actions, r.URL.Path, cfg)
 	}
 
 	api.log.Info(fmt.Sprintf("%s | %s | %d", r.Host, r.URL.Path, statusCode))
+	return url
 }
 
 // getEndpointEntry returns the Endpoint object for an incoming request, if it cannot be found immediatly we check all of them for parameter matching
-func (api *HTTPAPI) getEndpointEntry(r *http.Request) (string, *config.Endpoint, *HTTPError) {
-	urlEntry, found := api.cfg.Endpoints[r.URL.Path]
+func getEndpointEntry(cfg *config.Config, r *http.Request) (string, *config.Endpoint, *HTTPError) {
+	method := strings.ToLower(r.Method)
+
+	urlEntry, found := cfg.Endpoints[r.URL.Path]
 	if found {
-		if entry, found := urlEntry[strings.ToLower(r.Method)]; found {
+		if entry, found := urlEntry[method]; found {
 			return r.URL.Path, entry, nil
 		}
 		return "", nil, &HTTPError{"Method For URL Not Found", http.StatusMethodNotAllowed}
 	}
 
-	splitIncomingURL := strings.Split(r.URL.Path, "/")
-
-	for url, data := range api.cfg.Endpoints {
-		splitURL := strings.Split(url, "/")
-		splitURLLen := len(splitURL)
-
-		if len(splitIncomingURL) == splitURLLen {
-			for i, incomingBlock := range splitIncomingURL {
-				staticBlock := splitURL[i]
-
-				// its the same and we're not the last item, skip to the next block
-				if staticBlock == incomingBlock && (i+1) != splitURLLen {
-					continue
-				}
+	var notAllowed *HTTPError
+	for url, data := range cfg.Endpoints {
+		pathParams, matched := MatchPath(url, r.URL.Path)
+		if !matched {
+			continue
+		}
 
-				// its a parameter point, get the value and ensure its the correct type
-				if string(staticBlock[0]) == ":" {
-					if endpoint, found := data[strings.ToLower(r.Method)]; found {
-						if pe, found := endpoint.Params.Path[staticBlock[1:]]; found {
-							if !AssertValidType(interface{}(incomingBlock), pe.Type) {
-								return "", nil, &HTTPError{fmt.Sprintf("Path Param Not Valid %s Value", pe.Type), http.StatusBadRequest}
-							}
-						}
-					} else {
-						return "", nil, &HTTPError{"Method For URL Not Found", http.StatusMethodNotAllowed}
-					}
-				}
+		entry, found := data[method]
+		if !found {
+			notAllowed = &HTTPError{"Method For URL Not Found", http.StatusMethodNotAllowed}
+			continue
+		}
 
-				// we're on the last item and all fields have been correct
-				if (i + 1) == splitURLLen {
-					if entry, found := data[strings.ToLower(r.Method)]; found {
-						return url, entry, nil
-					}
-					return "", nil, &HTTPError{"Method For URL Not Found", http.StatusMethodNotAllowed}
+		// its a parameter point, ensure each value is the correct type
+		if entry.Params != nil {
+			for name, value := range pathParams {
+				if pe, found := entry.Params.Path[name]; found && !AssertValidType(interface{}(value), pe.Type) {
+					return "", nil, &HTTPError{fmt.Sprintf("Path Param Not Valid %s Value", pe.Type), http.StatusBadRequest}
 				}
 			}
 		}
+
+		return url, entry, nil
 	}
 
+	if notAllowed != nil {
+		return "", nil, notAllowed
+	}
 	return "", nil, &HTTPError{"URL Not Found", http.StatusNotFound}
 }
 
@@ -207,9 +388,9 @@ func (api *HTTPAPI) setupErrorResponse(err *HTTPError, w http.ResponseWriter) {
 }
 
 // evaluateHeaders checks the request headers are valid
-func (api *HTTPAPI) evaluateHeaders(in *config.Recieves, r *http.Request) *HTTPError {
+func evaluateHeaders(in *config.Recieves, headers http.Header) *HTTPError {
 	for exHeaderKey, exHeaderValue := range in.Headers {
-		if inHeaderValue := r.Header.Get(exHeaderKey); inHeaderValue != exHeaderValue {
+		if inHeaderValue := headers.Get(exHeaderKey); inHeaderValue != exHeaderValue {
 			return &HTTPError{fmt.Sprintf("Header Value %s Not Found", exHeaderKey), http.StatusBadRequest}
 		}
 	}
@@ -217,18 +398,14 @@ func (api *HTTPAPI) evaluateHeaders(in *config.Recieves, r *http.Request) *HTTPE
 }
 
 // evaluateBody checks the request body is valid
-func (api *HTTPAPI) evaluateBody(in *config.Recieves, r *http.Request) *HTTPError {
+func evaluateBody(in *config.Recieves, rawBody []byte) *HTTPError {
 	var body map[string]interface{}
-	rawBody, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		return &HTTPError{"Error Reading Incoming Body", http.StatusInternalServerError}
-	}
 	if err := json.Unmarshal(rawBody, &body); err != nil {
 		return &HTTPError{"Error Decoding Incoming Body", http.StatusInternalServerError}
 	}
 
 	for exName, exType := range in.Body {
-		if err = AssertValidTypeFromPath(exName, exType, body); err != nil {
+		if err := AssertValidTypeFromPath(exName, exType, body); err != nil {
 			return &HTTPError{err.Error(), http.StatusBadRequest}
 		}
 	}
@@ -236,8 +413,49 @@ func (api *HTTPAPI) evaluateBody(in *config.Recieves, r *http.Request) *HTTPErro
 	return nil
 }
 
-// setupResponse sets up the response to the users request based on the loaded cfg
-func (api *HTTPAPI) setupResponse(url string, responses map[int]*config.Response, w http.ResponseWriter) int {
+// evaluateXPath checks the values selected from an XML request body are valid
+func evaluateXPath(in *config.Recieves, doc *XMLNode) *HTTPError {
+	for exPath, exType := range in.XPath {
+		values, err := doc.XPath(exPath)
+		if err != nil {
+			return &HTTPError{err.Error(), http.StatusInternalServerError}
+		}
+		if len(values) == 0 {
+			return &HTTPError{fmt.Sprintf("XPath %s Not Found", exPath), http.StatusBadRequest}
+		}
+		if !AssertValidType(values[0], exType) {
+			return &HTTPError{fmt.Sprintf("%s Is Invalid Expected Type %s", exPath, exType), http.StatusBadRequest}
+		}
+	}
+	return nil
+}
+
+// readBody reads the request body, the body is replaced so it can be read again
+func readBody(r *http.Request) ([]byte, *HTTPError) {
+	rawBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, &HTTPError{"Error Reading Incoming Body", http.StatusInternalServerError}
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(rawBody))
+	return rawBody, nil
+}
+
+// readXMLBody parses the request body as XML, the body is replaced so it can be read again
+func readXMLBody(r *http.Request) (*XMLNode, *HTTPError) {
+	rawBody, httpErr := readBody(r)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	doc, err := ParseXML(rawBody)
+	if err != nil {
+		return nil, &HTTPError{"Error Decoding Incoming Body", http.StatusBadRequest}
+	}
+	return doc, nil
+}
+
+// setupResponse sets up the response to the users request based on the loaded cfg, returning the response chosen or nil on error
+func (api *HTTPAPI) setupResponse(url string, responses map[int]*config.Response, data *templateData, w http.ResponseWriter) (int, *config.Response) {
 	var statusCode int
 	var resp *config.Response
 
@@ -269,56 +487,54 @@ func (api *HTTPAPI) setupResponse(url string, responses map[int]*config.Response
 		}
 	}
 
+	body, err := api.responseBody(resp, data)
+	if err != nil {
+		api.setupErrorResponse(&HTTPError{
+			fmt.Sprintf("Unable To Write Response Body For Endpoint %s: %s", url, err.Error()),
+			http.StatusInternalServerError,
+		}, w)
+		return http.StatusInternalServerError, nil
+	}
+
 	if len(resp.Headers) > 0 {
 		for headerName, headerVal := range resp.Headers {
 			w.Header().Set(headerName, headerVal)
 		}
 	}
-
-	w.WriteHeader(statusCode)
-
-	if resp.Body != nil {
-		if data, err := json.Marshal(resp.Body); err == nil {
-			w.Write(data)
-		} else {
-			api.setupErrorResponse(&HTTPError{
-				fmt.Sprintf("Unable To Write Response Body For Endpoint %s: %s", url, err.Error()),
-				http.StatusInternalServerError,
-			}, w)
-		}
+	if len(w.Header().Get("Content-Type")) == 0 && (resp.Fault != nil || len(data.Operation) > 0) {
+		w.Header().Set("Content-Type", soapContentType)
 	}
 
-	return statusCode
-}
-
-// addEndpointToStats adds the given url to the statistics with zero-values for all status codes
-func (api *HTTPAPI) addEndpointToStats(url string, responses map[int]*config.Response) {
-	stats := make(map[int]int, len(responses))
-
-	for statusCode := range responses {
-		stats[statusCode] = 0
+	w.WriteHeader(statusCode)
+	if len(body) > 0 {
+		w.Write(body)
 	}
 
-	api.stats[url] = stats
+	return statusCode, resp
 }
 
-// statsHandler returns the current application stats as JSON
-func (api *HTTPAPI) statsHandler(w http.ResponseWriter) {
-	w.Header().Add("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	if data, err := json.Marshal(api.stats); err == nil {
-		w.Write(data)
-	} else {
-		api.setupErrorResponse(&HTTPError{
-			fmt.Sprintf("Unable To Write Response Body For Endpoint /stats: %s", err.Error()),
-			http.StatusInternalServerError,
-		}, w)
+// responseBody generates the body for the given response, templates are rendered with the incoming request data
+func (api *HTTPAPI) responseBody(resp *config.Response, data *templateData) ([]byte, error) {
+	switch {
+	case resp.Fault != nil:
+		return soapFaultEnvelope(resp.Fault), nil
+	case len(resp.RawBody) > 0:
+		return renderTemplate(resp.RawBody, data)
+	case len(resp.BodyFile) > 0:
+		content, err := ioutil.ReadFile(resp.BodyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Unable To Read Body File %s: %s", resp.BodyFile, err.Error())
+		}
+		return renderTemplate(string(content), data)
+	case resp.Body != nil:
+		return json.Marshal(resp.Body)
+	default:
+		return nil, nil
 	}
 }
 
-// exitHandler quits the application
-func (api *HTTPAPI) exitHandler() {
-	api.log.Info("Exit Requested, Shutting Down...")
-	os.Exit(0)
+// observeRejected records a request rejected for the given reason in both the stats and metrics
+func (api *HTTPAPI) observeRejected(url, method, reason string) {
+	api.metrics.ObserveValidationFailure(url, reason)
+	api.stats.ObserveRejected(url, method, reason)
 }
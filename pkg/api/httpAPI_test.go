@@ -0,0 +1,198 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+// testReloadConfig returns a config serving the given URLs with a 200, along with a SOAP endpoint holding the given operations
+func testReloadConfig(urls []string, operations []string, proxy *config.Proxy) *config.Config {
+	cfg := &config.Config{Version: 2.0, Endpoints: make(map[string]map[string]*config.Endpoint), Proxy: proxy}
+	for _, url := range urls {
+		cfg.Endpoints[url] = map[string]*config.Endpoint{"get": {Response: http.StatusOK}}
+	}
+	if len(operations) > 0 {
+		soap := make(map[string]*config.Endpoint, len(operations))
+		for _, operation := range operations {
+			soap[operation] = &config.Endpoint{Responses: map[int]*config.Response{200: {RawBody: "<" + operation + "/>", Weight: 100}}}
+		}
+		cfg.Endpoints["/soap"] = map[string]*config.Endpoint{"post": {SOAP: soap}}
+	}
+	return cfg
+}
+
+// serveTestRequest serves a request through the API, SOAP requests are sent when an action is given
+func serveTestRequest(api *HTTPAPI, method, path, action string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	if len(action) > 0 {
+		r = httptest.NewRequest(method, path, strings.NewReader(soapRequestBody("<Request/>")))
+		r.Header.Set("SOAPAction", action)
+	}
+	w := httptest.NewRecorder()
+	api.mux.ServeHTTP(w, r)
+	return w
+}
+
+// TestReload1 ensures requests are served by the new config once it is swapped in, and invalid args are rejected
+func TestReload1(t *testing.T) {
+	api := NewHTTPAPI(logger.NewLogger("std"), testReloadConfig([]string{"/a"}, nil, nil), nil)
+
+	if w := serveTestRequest(api, http.MethodGet, "/a", ""); w.Code != http.StatusOK {
+		t.Fatalf("Unexpected Status Code Before Reload: %d", w.Code)
+	}
+
+	next := testReloadConfig([]string{"/b"}, nil, nil)
+	if err := api.Reload(next, false); err != nil {
+		t.Fatalf("Unexpected Error Reloading: %s", err.Error())
+	}
+	if cfg, _ := api.current(); cfg != next {
+		t.Errorf("Config Was Not Swapped")
+	}
+	if w := serveTestRequest(api, http.MethodGet, "/a", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Removed Endpoint Still Served: %d", w.Code)
+	}
+	if w := serveTestRequest(api, http.MethodGet, "/b", ""); w.Code != http.StatusOK {
+		t.Errorf("Added Endpoint Not Served: %d", w.Code)
+	}
+
+	if err := api.Reload(nil, false); err == nil {
+		t.Errorf("Nil Config Returned No Error")
+	}
+}
+
+// TestReload2 ensures a config which cannot be loaded leaves the active config, proxy and stats in place
+func TestReload2(t *testing.T) {
+	upstream := testUpstream("upstream", http.StatusOK)
+	defer upstream.Close()
+
+	active := testReloadConfig([]string{"/a"}, nil, &config.Proxy{Target: upstream.URL})
+	api := NewHTTPAPI(logger.NewLogger("std"), active, nil)
+	serveTestRequest(api, http.MethodGet, "/a", "")
+	_, activeProxy := api.current()
+
+	if err := api.Reload(testReloadConfig([]string{"/b"}, nil, &config.Proxy{Target: "http://[::1"}), false); err == nil {
+		t.Fatalf("Invalid Proxy Target Returned No Error")
+	}
+
+	if cfg, proxy := api.current(); cfg != active || proxy != activeProxy {
+		t.Errorf("Active Config Was Replaced By A Config Which Failed To Load")
+	}
+	if stats := api.stats.Snapshot(); stats.Endpoints["/a"]["get"].StatusCodes[http.StatusOK] != 1 {
+		t.Errorf("Stats Were Changed By A Config Which Failed To Load: %+v", stats.Endpoints)
+	}
+	if w := serveTestRequest(api, http.MethodGet, "/missing", ""); w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "upstream") {
+		t.Errorf("Active Proxy Not Used: %d %s", w.Code, w.Body.String())
+	}
+}
+
+// TestReload3 ensures stats are kept for endpoints and SOAP operations which still exist when asked, otherwise reset
+func TestReload3(t *testing.T) {
+	operations := []string{"urn:payments#Authorise", "Refund"}
+	for _, keepStats := range []bool{true, false} {
+		api := NewHTTPAPI(logger.NewLogger("std"), testReloadConfig([]string{"/a", "/b"}, operations, nil), nil)
+		serveTestRequest(api, http.MethodGet, "/a", "")
+		serveTestRequest(api, http.MethodGet, "/b", "")
+		for _, operation := range operations {
+			if w := serveTestRequest(api, http.MethodPost, "/soap", operation); w.Code != http.StatusOK {
+				t.Fatalf("Unexpected Status Code For %s: %d", operation, w.Code)
+			}
+		}
+
+		if err := api.Reload(testReloadConfig([]string{"/a"}, operations[:1], nil), keepStats); err != nil {
+			t.Fatalf("Unexpected Error Reloading: %s", err.Error())
+		}
+
+		stats := api.stats.Snapshot()
+		kept := len(stats.Endpoints["/a"]) > 0 && len(stats.Endpoints["/soap#urn:payments#Authorise"]) > 0
+		switch {
+		case keepStats && (!kept || len(stats.Endpoints) != 2):
+			t.Errorf("Stats Not Kept For Remaining Endpoints Only: %+v", stats.Endpoints)
+		case !keepStats && len(stats.Endpoints) != 0:
+			t.Errorf("Stats Not Reset: %+v", stats.Endpoints)
+		}
+	}
+}
+
+// TestReload4 ensures the proxy is replaced or removed with the config, its stats kept when asked
+func TestReload4(t *testing.T) {
+	first, second := testUpstream("first", http.StatusOK), testUpstream("second", http.StatusCreated)
+	defer first.Close()
+	defer second.Close()
+
+	api := NewHTTPAPI(logger.NewLogger("std"), testReloadConfig([]string{"/a"}, nil, &config.Proxy{Target: first.URL}), nil)
+	if w := serveTestRequest(api, http.MethodGet, "/missing", ""); !strings.HasPrefix(w.Body.String(), "first") {
+		t.Fatalf("Request Not Proxied To First Upstream: %d %s", w.Code, w.Body.String())
+	}
+
+	if err := api.Reload(testReloadConfig([]string{"/a"}, nil, &config.Proxy{Target: second.URL}), true); err != nil {
+		t.Fatalf("Unexpected Error Reloading: %s", err.Error())
+	}
+	if w := serveTestRequest(api, http.MethodGet, "/missing", ""); w.Code != http.StatusCreated || !strings.HasPrefix(w.Body.String(), "second") {
+		t.Errorf("Request Not Proxied To Second Upstream: %d %s", w.Code, w.Body.String())
+	}
+	_, proxy := api.current()
+	if stats := proxy.Stats(); stats["*"][http.StatusOK] != 1 || stats["*"][http.StatusCreated] != 1 {
+		t.Errorf("Proxy Stats Not Kept: %v", stats)
+	}
+
+	if err := api.Reload(testReloadConfig([]string{"/a"}, nil, nil), true); err != nil {
+		t.Fatalf("Unexpected Error Reloading: %s", err.Error())
+	}
+	if w := serveTestRequest(api, http.MethodGet, "/missing", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Request Proxied After The Proxy Was Removed: %d %s", w.Code, w.Body.String())
+	}
+}
+
+// TestServeRequest2 ensures a response body which fails to render returns a 500 without running the response actions
+func TestServeRequest2(t *testing.T) {
+	req := new(mockRequester)
+	cfg := &config.Config{
+		Version:  2.0,
+		Services: map[string]*config.Service{"api": {Hostname: "localhost", Port: 8080}},
+		Requests: map[string]*config.Request{"ping": {URL: "/ping", Method: "get"}},
+		Endpoints: map[string]map[string]*config.Endpoint{"/broken": {"get": {Responses: map[int]*config.Response{200: {
+			RawBody: "{{.Missing",
+			Weight:  100,
+			Actions: []*config.Action{{Request: &config.RequestAction{Target: "api", ID: "ping"}}},
+		}}}}},
+	}
+	api := NewHTTPAPI(logger.NewLogger("std"), cfg, req)
+
+	if w := serveTestRequest(api, http.MethodGet, "/broken", ""); w.Code != http.StatusInternalServerError {
+		t.Errorf("Unexpected Status Code For A Broken Template: %d", w.Code)
+	}
+	if len(req.sent) > 0 {
+		t.Errorf("Response Actions Run For A Broken Template: %v", req.sent)
+	}
+}
+
+// TestReload5 ensures endpoints added at runtime are discarded by a reload, whilst listeners keep the config they started with
+func TestReload5(t *testing.T) {
+	api := NewHTTPAPI(logger.NewLogger("std"), testReloadConfig([]string{"/a"}, nil, nil), nil)
+	listener := testSocketAPI(t, "tcp", "line", []*config.ListenerRule{{Match: "^PING", Reply: "PONG"}})
+	api.AddListener(listener)
+
+	w := httptest.NewRecorder()
+	api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/__admin/v1/endpoints", strings.NewReader(`{"/b": {"get": {"response": 200}}}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Unable To Add Endpoint: %d %s", w.Code, w.Body.String())
+	}
+	if w := serveTestRequest(api, http.MethodGet, "/b", ""); w.Code != http.StatusOK {
+		t.Fatalf("Endpoint Added At Runtime Not Served: %d", w.Code)
+	}
+
+	if err := api.Reload(testReloadConfig([]string{"/a"}, nil, nil), false); err != nil {
+		t.Fatalf("Unexpected Error Reloading: %s", err.Error())
+	}
+	if w := serveTestRequest(api, http.MethodGet, "/b", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Endpoint Added At Runtime Served After Reload: %d", w.Code)
+	}
+	if reply, _ := listener.handleData([]byte("PING"), "test"); string(reply) != "PONG\n" {
+		t.Errorf("Listener Changed By Reload: %q", reply)
+	}
+}
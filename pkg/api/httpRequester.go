@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -52,7 +53,8 @@ func (h *HTTPRequester) Request(tgt *config.Service, req *config.Request) error
 
 // setupRequest configured an http.Request object according to the specified config input
 func (h *HTTPRequester) setupRequest(tgt *config.Service, req *config.Request) (*http.Request, error) {
-	var body *bytes.Buffer
+	// left as a nil interface when there is no body, a nil *bytes.Buffer is dereferenced by http.NewRequest
+	var body io.Reader
 	url := fmt.Sprintf("%s://%s:%d%s", req.Protocol, tgt.Hostname, tgt.Port, req.URL)
 
 	if req.Body != nil && len(req.Body) > 0 {
@@ -87,8 +89,13 @@ func (h *HTTPRequester) makeRequest(req *http.Request) (resp *http.Response, err
 
 // validateResponse ensures the givne http.Response is correct according to the specified request
 func (h *HTTPRequester) validateResponse(resp *http.Response, req *config.Request) error {
+	// any response is accepted when none is expected
+	if req.ExpectedResponse == nil {
+		return nil
+	}
+
 	if resp.StatusCode != req.ExpectedResponse.StatusCode {
-		return fmt.Errorf("Status Code MisMatch, Expected %d Got %d", resp.StatusCode, req.ExpectedResponse.StatusCode)
+		return fmt.Errorf("Status Code MisMatch, Expected %d Got %d", req.ExpectedResponse.StatusCode, resp.StatusCode)
 	}
 
 	// validate response body if required
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// TestValidateResponse1 ensures an unexpected status code is reported with the expected code first
+func TestValidateResponse1(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusInternalServerError, Header: make(http.Header)}
+	req := &config.Request{ExpectedResponse: &config.Response{StatusCode: http.StatusOK}}
+
+	err := NewHTTPRequester().validateResponse(resp, req)
+	if err == nil || err.Error() != "Status Code MisMatch, Expected 200 Got 500" {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
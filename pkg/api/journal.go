@@ -0,0 +1,300 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// journalBodyLimit is the number of bytes of each request body kept in the journal
+const journalBodyLimit = 64 * 1024
+
+// JournalEntry represents a single request received by the API, along with how it was served
+type JournalEntry struct {
+	ID            int                 `json:"id"`
+	ReceivedAt    time.Time           `json:"receivedAt"`
+	RespondedAt   time.Time           `json:"respondedAt"`
+	Method        string              `json:"method"`
+	Path          string              `json:"path"`
+	Query         map[string][]string `json:"query"`
+	Headers       map[string][]string `json:"headers"`
+	Body          string              `json:"body"`
+	BodyTruncated bool                `json:"bodyTruncated,omitempty"`
+	Endpoint      string              `json:"endpoint,omitempty"` // URL of the matched endpoint, with the SOAP operation after a '#'
+	StatusCode    int                 `json:"statusCode"`
+}
+
+// Journal holds the most recent requests received by the API, the oldest are discarded once it is full
+type Journal struct {
+	mutex   sync.Mutex
+	entries []*JournalEntry
+	next    int // index the next entry is written to
+	count   int // number of entries held
+	lastID  int
+	changed chan struct{} // closed and replaced whenever an entry is recorded, waking every waiter
+}
+
+// NewJournal creates a new instance of Journal holding at most size requests
+func NewJournal(size int) *Journal {
+	if size <= 0 {
+		return nil
+	}
+	return &Journal{entries: make([]*JournalEntry, size), changed: make(chan struct{})}
+}
+
+// newJournalEntry creates an entry for an incoming request, reading its body and replacing it so it can be read again
+func newJournalEntry(r *http.Request) *JournalEntry {
+	entry := &JournalEntry{
+		ReceivedAt: time.Now(),
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Query:      r.URL.Query(),
+		Headers:    r.Header.Clone(),
+	}
+
+	if r.Body != nil {
+		if rawBody, err := ioutil.ReadAll(r.Body); err == nil {
+			r.Body = ioutil.NopCloser(bytes.NewReader(rawBody))
+			if len(rawBody) > journalBodyLimit {
+				rawBody, entry.BodyTruncated = rawBody[:journalBodyLimit], true
+			}
+			entry.Body = string(rawBody)
+		}
+	}
+	return entry
+}
+
+// Record adds an entry to the journal, discarding the oldest entry if it is full
+func (j *Journal) Record(entry *JournalEntry) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.lastID++
+	entry.ID = j.lastID
+	j.entries[j.next] = entry
+	j.next = (j.next + 1) % len(j.entries)
+	if j.count < len(j.entries) {
+		j.count++
+	}
+
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// Query returns every entry matched by the given matcher in the order they were received
+func (j *Journal) Query(matcher *RequestMatcher) []*JournalEntry {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	result := make([]*JournalEntry, 0)
+	for i := 0; i < j.count; i++ {
+		entry := j.entries[(j.next-j.count+i+len(j.entries))%len(j.entries)]
+		if matcher == nil || matcher.Matches(entry) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+/*Wait blocks until the journal holds count entries matched by the matcher, returning the first count of them. Entries
+recorded before the call are included, so a request which arrives before waiting begins is not missed. Returns false with the
+entries matched so far if the timeout passes or done is closed first */
+func (j *Journal) Wait(matcher *RequestMatcher, count int, timeout time.Duration, done <-chan struct{}) ([]*JournalEntry, bool) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		// taken before querying so an entry recorded in between still wakes the wait
+		j.mutex.Lock()
+		changed := j.changed
+		j.mutex.Unlock()
+
+		entries := j.Query(matcher)
+		if len(entries) >= count {
+			return entries[:count], true
+		}
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return entries, false
+		case <-done:
+			return entries, false
+		}
+	}
+}
+
+// Clear removes every entry from the journal
+func (j *Journal) Clear() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.entries = make([]*JournalEntry, len(j.entries))
+	j.next, j.count = 0, 0
+}
+
+// RequestMatcher selects journal entries, every criteria set must match
+type RequestMatcher struct {
+	Method   string
+	Path     string // an endpoint URL, ':name' segments match any value
+	Endpoint string
+	Since    time.Time
+	Until    time.Time
+	Headers  map[string]*regexp.Regexp // header name -> regex matched against any of its values
+	Body     *regexp.Regexp
+}
+
+/*parseRequestMatcher creates a matcher from the query params of an admin request: 'method', 'path', 'endpoint', 'since' and
+'until' as RFC 3339 times, 'header' as 'Name:regex' which may be repeated, and 'body' as a regex */
+func parseRequestMatcher(query url.Values) (*RequestMatcher, *HTTPError) {
+	matcher := &RequestMatcher{
+		Method:   query.Get("method"),
+		Path:     query.Get("path"),
+		Endpoint: query.Get("endpoint"),
+		Headers:  make(map[string]*regexp.Regexp),
+	}
+
+	for name, field := range map[string]*time.Time{"since": &matcher.Since, "until": &matcher.Until} {
+		if value := query.Get(name); len(value) > 0 {
+			parsed, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, &HTTPError{fmt.Sprintf("Query Param %s Is Not An RFC 3339 Time", name), http.StatusBadRequest}
+			}
+			*field = parsed
+		}
+	}
+
+	for _, header := range query["header"] {
+		i := strings.Index(header, ":")
+		if i <= 0 {
+			return nil, &HTTPError{fmt.Sprintf("Header Matcher %s Is Not Name:regex", header), http.StatusBadRequest}
+		}
+		pattern, err := regexp.Compile(header[i+1:])
+		if err != nil {
+			return nil, &HTTPError{fmt.Sprintf("Invalid Header Regex %s: %s", header[i+1:], err.Error()), http.StatusBadRequest}
+		}
+		matcher.Headers[http.CanonicalHeaderKey(header[:i])] = pattern
+	}
+
+	if body := query.Get("body"); len(body) > 0 {
+		pattern, err := regexp.Compile(body)
+		if err != nil {
+			return nil, &HTTPError{fmt.Sprintf("Invalid Body Regex %s: %s", body, err.Error()), http.StatusBadRequest}
+		}
+		matcher.Body = pattern
+	}
+
+	return matcher, nil
+}
+
+// Matches checks if the given entry meets every criteria of the matcher
+func (m *RequestMatcher) Matches(entry *JournalEntry) bool {
+	switch {
+	case len(m.Method) > 0 && !strings.EqualFold(m.Method, entry.Method):
+		return false
+	case len(m.Path) > 0 && m.Path != entry.Path && !matchesPath(m.Path, entry.Path):
+		return false
+	case len(m.Endpoint) > 0 && m.Endpoint != entry.Endpoint:
+		return false
+	case !m.Since.IsZero() && entry.ReceivedAt.Before(m.Since):
+		return false
+	case !m.Until.IsZero() && entry.ReceivedAt.After(m.Until):
+		return false
+	case m.Body != nil && !m.Body.MatchString(entry.Body):
+		return false
+	}
+
+	for name, pattern := range m.Headers {
+		if !matchesAny(pattern, entry.Headers[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesPath checks if the path is matched by the given endpoint URL
+func matchesPath(pattern, path string) bool {
+	_, matched := MatchPath(pattern, path)
+	return matched
+}
+
+// matchesAny checks if the regex matches any of the given values
+func matchesAny(pattern *regexp.Regexp, values []string) bool {
+	for _, value := range values {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestsHandler returns the journal entries matched by the query params as JSON, the most recent 'limit' when it is given
+func (api *HTTPAPI) requestsHandler(w http.ResponseWriter, r *http.Request) int {
+	matcher, err := parseRequestMatcher(r.URL.Query())
+	if err != nil {
+		return api.adminError(w, err)
+	}
+
+	entries := api.journal.Query(matcher)
+	if value := r.URL.Query().Get("limit"); len(value) > 0 {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return api.adminError(w, &HTTPError{"Query Param limit Is Not A Positive Integer", http.StatusBadRequest})
+		}
+		if limit < len(entries) {
+			entries = entries[len(entries)-limit:]
+		}
+	}
+
+	return api.writeJSON(w, http.StatusOK, map[string]interface{}{"requests": entries})
+}
+
+/*waitRequestsHandler long-polls until 'count' requests, default 1, are matched by the query params or the 'timeout', a
+duration defaulting to 30s, passes. The matched requests are returned as JSON, with matched false when the timeout passes first */
+func (api *HTTPAPI) waitRequestsHandler(w http.ResponseWriter, r *http.Request) int {
+	matcher, err := parseRequestMatcher(r.URL.Query())
+	if err != nil {
+		return api.adminError(w, err)
+	}
+
+	count, timeout := 1, 30*time.Second
+	if value := r.URL.Query().Get("count"); len(value) > 0 {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return api.adminError(w, &HTTPError{"Query Param count Is Not A Positive Integer", http.StatusBadRequest})
+		}
+		count = parsed
+	}
+	if value := r.URL.Query().Get("timeout"); len(value) > 0 {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return api.adminError(w, &HTTPError{"Query Param timeout Is Not A Positive Duration", http.StatusBadRequest})
+		}
+		timeout = parsed
+	}
+
+	entries, matched := api.journal.Wait(matcher, count, timeout, r.Context().Done())
+	// a 408 would be retried by some clients and proxies, so a timeout is a 200 with matched false
+	if !matched {
+		return api.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"matched":  false,
+			"error":    fmt.Sprintf("Timed Out Waiting For %d Matching Requests After %s", count, timeout),
+			"requests": entries,
+		})
+	}
+	return api.writeJSON(w, http.StatusOK, map[string]interface{}{"matched": true, "requests": entries})
+}
+
+// clearRequestsHandler removes every entry from the journal
+func (api *HTTPAPI) clearRequestsHandler(w http.ResponseWriter, r *http.Request) int {
+	api.journal.Clear()
+	w.WriteHeader(http.StatusNoContent)
+	return http.StatusNoContent
+}
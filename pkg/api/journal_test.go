@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestJournal1 ensures the oldest entries are discarded once the journal is full, and the rest are returned in order
+func TestJournal1(t *testing.T) {
+	journal := NewJournal(3)
+	for _, path := range []string{"/a", "/b", "/c", "/d"} {
+		journal.Record(&JournalEntry{Path: path})
+	}
+
+	entries := journal.Query(nil)
+	if len(entries) != 3 || entries[0].Path != "/b" || entries[2].Path != "/d" || entries[2].ID != 4 {
+		t.Errorf("Unexpected Entries: %+v", entries)
+	}
+
+	journal.Clear()
+	if entries := journal.Query(nil); len(entries) != 0 {
+		t.Errorf("Entries Remain After Clear: %d", len(entries))
+	}
+}
+
+// TestRequestMatcher1 ensures each criteria parsed from query params must match an entry
+func TestRequestMatcher1(t *testing.T) {
+	now := time.Now()
+	entry := &JournalEntry{
+		ReceivedAt: now,
+		Method:     "POST",
+		Path:       "/users/24",
+		Headers:    map[string][]string{"Content-Type": {"application/json"}},
+		Body:       `{"name": "test"}`,
+		Endpoint:   "/users/:id",
+	}
+
+	for query, expected := range map[string]bool{
+		"":                                   true,
+		"method=post&path=/users/:id":        true,
+		"path=/users/24&endpoint=/users/:id": true,
+		"header=content-type:json&body=name.*test":                                 true,
+		"since=" + url.QueryEscape(now.Add(-time.Second).Format(time.RFC3339Nano)): true,
+		"method=get":                 false,
+		"path=/users":                false,
+		"header=Accept:json":         false,
+		"body=missing":               false,
+		"until=2000-01-01T00:00:00Z": false,
+	} {
+		values, _ := url.ParseQuery(query)
+		matcher, err := parseRequestMatcher(values)
+		if err != nil {
+			t.Fatalf("Unable To Parse Matcher %s: %s", query, err.Error())
+		}
+		if matcher.Matches(entry) != expected {
+			t.Errorf("Matcher %s Did Not Return %t", query, expected)
+		}
+	}
+
+	for _, query := range []string{"since=yesterday", "header=nocolon", "body=["} {
+		values, _ := url.ParseQuery(query)
+		if _, err := parseRequestMatcher(values); err == nil {
+			t.Errorf("Invalid Matcher %s Returned No Error", query)
+		}
+	}
+}
+
+// TestRequestsHandler1 ensures requests to the endpoints are journalled with their body, endpoint and status code
+func TestRequestsHandler1(t *testing.T) {
+	api := testHTTPAPI(nil)
+	api.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stats", strings.NewReader("first")))
+	api.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/missing", strings.NewReader("second")))
+
+	w := httptest.NewRecorder()
+	api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/__admin/v1/requests?method=get", nil))
+	var result struct {
+		Requests []*JournalEntry `json:"requests"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Unable To Unmarshal Requests: %s", err.Error())
+	}
+	if len(result.Requests) != 1 {
+		t.Fatalf("Unexpected Number Of Requests: %d", len(result.Requests))
+	}
+	if entry := result.Requests[0]; entry.Body != "first" || entry.Endpoint != "/stats" || entry.StatusCode != http.StatusTeapot {
+		t.Errorf("Unexpected Request Entry: %+v", entry)
+	}
+
+	api.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/__admin/v1/requests", nil))
+	if entries := api.journal.Query(&RequestMatcher{Body: regexp.MustCompile("second")}); len(entries) != 0 {
+		t.Errorf("Requests Remain After Clear: %d", len(entries))
+	}
+}
+
+// TestJournalWait1 ensures waiting returns requests already received, those received whilst waiting, and times out otherwise
+func TestJournalWait1(t *testing.T) {
+	journal := NewJournal(10)
+	journal.Record(&JournalEntry{Path: "/a"})
+
+	if entries, matched := journal.Wait(&RequestMatcher{Path: "/a"}, 1, time.Second, nil); !matched || len(entries) != 1 {
+		t.Errorf("Request Already Received Not Returned: %v", entries)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		journal.Record(&JournalEntry{Path: "/b"})
+		journal.Record(&JournalEntry{Path: "/b"})
+	}()
+	if entries, matched := journal.Wait(&RequestMatcher{Path: "/b"}, 2, time.Second, nil); !matched || len(entries) != 2 {
+		t.Errorf("Requests Received Whilst Waiting Not Returned: %v", entries)
+	}
+
+	if _, matched := journal.Wait(&RequestMatcher{Path: "/c"}, 1, 10*time.Millisecond, nil); matched {
+		t.Errorf("Wait Matched A Request Never Received")
+	}
+}
+
+// TestWaitRequestsHandler1 ensures the wait route returns matched false when no request arrives before the timeout
+func TestWaitRequestsHandler1(t *testing.T) {
+	api := testHTTPAPI(nil)
+
+	w := httptest.NewRecorder()
+	api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/__admin/v1/requests/wait?path=/stats&timeout=10ms", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"matched":false`) {
+		t.Errorf("Unexpected Response To A Timed Out Wait: %d %s", w.Code, w.Body.String())
+	}
+
+	for path, statusCode := range map[string]int{
+		"/__admin/v1/requests/wait?timeout=soon": http.StatusBadRequest,
+		"/__admin/v1/requests/wait?count=0":      http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != statusCode {
+			t.Errorf("Unexpected Status Code For %s: %d", path, w.Code)
+		}
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		api.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stats", nil))
+	}()
+	w = httptest.NewRecorder()
+	api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/__admin/v1/requests/wait?path=/stats&timeout=1s", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"matched":true`) || !strings.Contains(w.Body.String(), `"endpoint":"/stats"`) {
+		t.Errorf("Request Received Whilst Waiting Not Returned: %d %s", w.Code, w.Body.String())
+	}
+}
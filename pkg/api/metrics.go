@@ -0,0 +1,225 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// metricsContentType is the content type of the Prometheus text exposition format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// latencyBuckets are the upper bounds in seconds of every histogram bucket, the Prometheus client defaults
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metricValue is the value of a metric for a single set of label values
+type metricValue struct {
+	labels  []string
+	count   float64  // value of a counter, or the number of observations of a histogram
+	sum     float64  // sum of every observation of a histogram
+	buckets []uint64 // observations in each histogram bucket, not cumulative
+}
+
+// metricVec is a counter or histogram partitioned by a fixed set of labels
+type metricVec struct {
+	name   string
+	help   string
+	kind   string // counter or histogram
+	labels []string
+	values map[string]*metricValue // label values joined with '\x00' -> value
+}
+
+// newMetricVec creates a new instance of metricVec
+func newMetricVec(name, help, kind string, labels ...string) *metricVec {
+	return &metricVec{name: name, help: help, kind: kind, labels: labels, values: make(map[string]*metricValue)}
+}
+
+// value returns the value for the given label values, adding it on first use
+func (v *metricVec) value(labels []string) *metricValue {
+	key := strings.Join(labels, "\x00")
+	value, found := v.values[key]
+	if !found {
+		value = &metricValue{labels: labels}
+		if v.kind == "histogram" {
+			value.buckets = make([]uint64, len(latencyBuckets))
+		}
+		v.values[key] = value
+	}
+	return value
+}
+
+// inc increments a counter
+func (v *metricVec) inc(labels ...string) {
+	v.value(labels).count++
+}
+
+// observe adds a duration to a histogram, observations above the largest bucket are only counted by '+Inf'
+func (v *metricVec) observe(duration time.Duration, labels ...string) {
+	value := v.value(labels)
+	seconds := duration.Seconds()
+	value.count++
+	value.sum += seconds
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			value.buckets[i]++
+			break
+		}
+	}
+}
+
+// write writes the metric in the Prometheus text format, ordered by label values so the output is stable
+func (v *metricVec) write(out *bytes.Buffer) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", v.name, v.help, v.name, v.kind)
+
+	keys := make([]string, 0, len(v.values))
+	for key := range v.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := v.values[key]
+		labels := formatLabels(v.labels, value.labels)
+		if v.kind == "counter" {
+			fmt.Fprintf(out, "%s%s %s\n", v.name, labels, formatFloat(value.count))
+			continue
+		}
+
+		// each bucket is labelled with its upper bound, 'le', after the labels of the histogram
+		names := append(append([]string{}, v.labels...), "le")
+		bucketLabels := func(bound string) string {
+			return formatLabels(names, append(append([]string{}, value.labels...), bound))
+		}
+
+		var cumulative uint64
+		for i, bound := range latencyBuckets {
+			cumulative += value.buckets[i]
+			fmt.Fprintf(out, "%s_bucket%s %d\n", v.name, bucketLabels(formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(out, "%s_bucket%s %s\n", v.name, bucketLabels("+Inf"), formatFloat(value.count))
+		fmt.Fprintf(out, "%s_sum%s %s\n", v.name, labels, formatFloat(value.sum))
+		fmt.Fprintf(out, "%s_count%s %s\n", v.name, labels, formatFloat(value.count))
+	}
+}
+
+// formatLabels formats label names and values as '{name="value",...}', escaping each value
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(values[i]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// labelEscaper escapes the characters which may not appear as is in a label value
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatFloat formats a sample value in its shortest form
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+/*Metrics counts the requests served and actions run, in the Prometheus text format. Unlike the stats, metrics are never reset
+by a reload so every counter only ever increases whilst the application runs */
+type Metrics struct {
+	mutex          sync.Mutex
+	requests       *metricVec
+	latency        *metricVec
+	unmatched      *metricVec
+	validation     *metricVec
+	actions        *metricVec
+	actionsLatency *metricVec
+}
+
+// NewMetrics creates a new instance of Metrics
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests:       newMetricVec("ministub_requests_total", "Requests received, by endpoint, method and status code.", "counter", "endpoint", "method", "status"),
+		latency:        newMetricVec("ministub_request_duration_seconds", "Time taken to respond to requests, by endpoint and method.", "histogram", "endpoint", "method"),
+		unmatched:      newMetricVec("ministub_unmatched_requests_total", "Requests matching no endpoint, by method.", "counter", "method"),
+		validation:     newMetricVec("ministub_validation_failures_total", "Requests rejected as invalid, by endpoint and reason.", "counter", "endpoint", "reason"),
+		actions:        newMetricVec("ministub_actions_total", "Follow-on actions run, by action type, request ID and outcome.", "counter", "action", "request", "outcome"),
+		actionsLatency: newMetricVec("ministub_action_duration_seconds", "Time taken by follow-on requests, by request ID and outcome.", "histogram", "request", "outcome"),
+	}
+}
+
+// ObserveRequest records a request responded to, the endpoint is empty when none was matched
+func (m *Metrics) ObserveRequest(endpoint, method string, statusCode int, duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.requests.inc(endpoint, method, strconv.Itoa(statusCode))
+	m.latency.observe(duration, endpoint, method)
+}
+
+// ObserveUnmatched records a request matching no endpoint
+func (m *Metrics) ObserveUnmatched(method string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.unmatched.inc(method)
+}
+
+// ObserveValidationFailure records a request rejected for the given reason, such as 'header' or 'body'
+func (m *Metrics) ObserveValidationFailure(endpoint, reason string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.validation.inc(endpoint, reason)
+}
+
+// ObserveActions records the outcome of each action run, the time taken is recorded for every request sent
+func (m *Metrics) ObserveActions(results []*ActionResult) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, result := range results {
+		outcome := "success"
+		if !result.Success {
+			outcome = "failure"
+		}
+		m.actions.inc(result.Action, result.Request, outcome)
+		if result.sent {
+			m.actionsLatency.observe(result.duration, result.Request, outcome)
+		}
+	}
+}
+
+// Text returns every metric in the Prometheus text format
+func (m *Metrics) Text() []byte {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	out := new(bytes.Buffer)
+	for _, metric := range []*metricVec{m.requests, m.latency, m.unmatched, m.validation, m.actions, m.actionsLatency} {
+		metric.write(out)
+	}
+	return out.Bytes()
+}
+
+// metricsHandler returns every metric in the Prometheus text format
+func (api *HTTPAPI) metricsHandler(w http.ResponseWriter, r *http.Request) int {
+	w.Header().Set("Content-Type", metricsContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(api.metrics.Text())
+	return http.StatusOK
+}
+
+// RunActions runs the given actions as ExecuteActions does, recording the outcome of each in the stats and metrics
+func (api *HTTPAPI) RunActions(actions []*config.Action, caller string, cfg *config.Config) []*ActionResult {
+	results := ExecuteActions(actions, caller, cfg, api.log, api.req)
+	api.observeActions(results)
+	return results
+}
+
+// observeActions records the outcome of each action in the stats and metrics
+func (api *HTTPAPI) observeActions(results []*ActionResult) {
+	api.metrics.ObserveActions(results)
+	api.stats.ObserveActions(results)
+}
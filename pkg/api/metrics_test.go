@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+// TestMetrics1 ensures counters and histograms are written in the Prometheus text format, with label values escaped
+func TestMetrics1(t *testing.T) {
+	metrics := NewMetrics()
+	metrics.ObserveRequest("/a", "GET", 200, 20*time.Millisecond)
+	metrics.ObserveRequest("/a", "GET", 200, 3*time.Second)
+	metrics.ObserveValidationFailure(`/"b"`, "header")
+	metrics.ObserveActions([]*ActionResult{
+		{Action: "delay", Success: true},
+		{Action: "request", Request: "ping", Success: false, sent: true, duration: time.Millisecond},
+	})
+
+	text := string(metrics.Text())
+	for _, line := range []string{
+		"# TYPE ministub_requests_total counter",
+		`ministub_requests_total{endpoint="/a",method="GET",status="200"} 2`,
+		"# TYPE ministub_request_duration_seconds histogram",
+		`ministub_request_duration_seconds_bucket{endpoint="/a",method="GET",le="0.01"} 0`,
+		`ministub_request_duration_seconds_bucket{endpoint="/a",method="GET",le="0.025"} 1`,
+		`ministub_request_duration_seconds_bucket{endpoint="/a",method="GET",le="5"} 2`,
+		`ministub_request_duration_seconds_bucket{endpoint="/a",method="GET",le="+Inf"} 2`,
+		`ministub_request_duration_seconds_sum{endpoint="/a",method="GET"} 3.02`,
+		`ministub_request_duration_seconds_count{endpoint="/a",method="GET"} 2`,
+		`ministub_validation_failures_total{endpoint="/\"b\"",reason="header"} 1`,
+		`ministub_actions_total{action="delay",request="",outcome="success"} 1`,
+		`ministub_actions_total{action="request",request="ping",outcome="failure"} 1`,
+		`ministub_action_duration_seconds_count{request="ping",outcome="failure"} 1`,
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("Metrics Missing Line: %s", line)
+		}
+	}
+}
+
+// TestMetricsHandler1 ensures requests served, unmatched and rejected are counted by the metrics route
+func TestMetricsHandler1(t *testing.T) {
+	cfg := &config.Config{
+		Version: 2.0,
+		Endpoints: map[string]map[string]*config.Endpoint{"/a": {"post": {
+			Response: http.StatusCreated,
+			Recieves: &config.Recieves{Headers: map[string]string{"X-Test": "true"}},
+		}}},
+	}
+	api := NewHTTPAPI(logger.NewLogger("std"), cfg, nil)
+
+	for _, r := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/a", nil),
+		httptest.NewRequest(http.MethodGet, "/missing", nil),
+	} {
+		api.mux.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	r := httptest.NewRequest(http.MethodPost, "/a", nil)
+	r.Header.Set("X-Test", "true")
+	api.mux.ServeHTTP(httptest.NewRecorder(), r)
+
+	w := httptest.NewRecorder()
+	api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/__admin/v1/metrics", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != metricsContentType {
+		t.Fatalf("Unexpected Metrics Response: %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	for _, line := range []string{
+		`ministub_requests_total{endpoint="/a",method="POST",status="201"} 1`,
+		`ministub_requests_total{endpoint="/a",method="POST",status="400"} 1`,
+		`ministub_requests_total{endpoint="",method="GET",status="404"} 1`,
+		`ministub_unmatched_requests_total{method="GET"} 1`,
+		`ministub_validation_failures_total{endpoint="/a",reason="header"} 1`,
+	} {
+		if !strings.Contains(w.Body.String(), line+"\n") {
+			t.Errorf("Metrics Missing Line: %s", line)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package api
+
+import "strings"
+
+// MatchPath checks whether an incoming path matches an endpoint url pattern, returning the values of any ':name' parameter segments
+func MatchPath(pattern, path string) (map[string]string, bool) {
+	splitPattern := strings.Split(pattern, "/")
+	splitPath := strings.Split(path, "/")
+
+	if len(splitPattern) != len(splitPath) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, block := range splitPattern {
+		switch {
+		case len(block) > 1 && block[0] == ':':
+			if len(splitPath[i]) == 0 {
+				return nil, false
+			}
+			params[block[1:]] = splitPath[i]
+		case block != splitPath[i]:
+			return nil, false
+		}
+	}
+
+	return params, true
+}
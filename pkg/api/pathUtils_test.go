@@ -0,0 +1,32 @@
+package api
+
+import "testing"
+
+// TestMatchPath1 ensures matching paths are returned with their parameter values
+func TestMatchPath1(t *testing.T) {
+	params, matched := MatchPath("/api/v1/job/:id/complete", "/api/v1/job/24/complete")
+	if !matched {
+		t.Fatalf("Matching Path Returned As Not Matched")
+	}
+	if params["id"] != "24" {
+		t.Errorf("Path Param 'id' Does Not Match '24': %s", params["id"])
+	}
+
+	if _, matched := MatchPath("/api/v1/test", "/api/v1/test"); !matched {
+		t.Errorf("Static Path Returned As Not Matched")
+	}
+}
+
+// TestMatchPath2 ensures paths differing in static segments, length or with empty parameters are not matched
+func TestMatchPath2(t *testing.T) {
+	for pattern, path := range map[string]string{
+		"/api/v1/job/:id/complete": "/api/v1/job/24/cancel",
+		"/api/v1/test":             "/api/v2/test",
+		"/api/:id":                 "/api/",
+		"/api/:id/":                "/api/24",
+	} {
+		if _, matched := MatchPath(pattern, path); matched {
+			t.Errorf("Path %s Returned As Matching Pattern %s", path, pattern)
+		}
+	}
+}
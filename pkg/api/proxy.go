@@ -0,0 +1,146 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+// proxyRoute is a path prefix and the upstream it is forwarded to
+type proxyRoute struct {
+	prefix string
+	target *url.URL
+	proxy  *httputil.ReverseProxy
+}
+
+// Proxy forwards requests matching no endpoint to upstream services
+type Proxy struct {
+	log    logger.Logger
+	routes []*proxyRoute          // longest prefix first, the global target last with an empty prefix
+	stats  map[string]map[int]int // route -> statusCode: count
+	mutex  sync.Mutex
+}
+
+// NewProxy creates a new instance of Proxy from the given definition
+func NewProxy(log logger.Logger, def *config.Proxy) (*Proxy, error) {
+	if log == nil || def == nil {
+		return nil, fmt.Errorf("Invalid Args")
+	}
+
+	p := &Proxy{
+		log:    log,
+		routes: make([]*proxyRoute, 0, len(def.Routes)+1),
+		stats:  make(map[string]map[int]int),
+	}
+
+	for prefix, target := range def.Routes {
+		route, err := newProxyRoute(prefix, target)
+		if err != nil {
+			return nil, err
+		}
+		p.routes = append(p.routes, route)
+	}
+	sort.Slice(p.routes, func(i, j int) bool { return len(p.routes[i].prefix) > len(p.routes[j].prefix) })
+
+	if len(def.Target) > 0 {
+		route, err := newProxyRoute("", def.Target)
+		if err != nil {
+			return nil, err
+		}
+		p.routes = append(p.routes, route)
+	}
+
+	return p, nil
+}
+
+// newProxyRoute creates a reverse proxy for the given upstream, requests are sent with the upstream as their Host
+func newProxyRoute(prefix, target string) (*proxyRoute, error) {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Proxy Target %s: %s", target, err.Error())
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(targetURL)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Host = targetURL.Host
+	}
+
+	return &proxyRoute{prefix: prefix, target: targetURL, proxy: proxy}, nil
+}
+
+// Forward sends the request to the matching upstream and writes its response, returns false if no upstream matches the path
+func (p *Proxy) Forward(w http.ResponseWriter, r *http.Request) bool {
+	route := p.match(r.URL.Path)
+	if route == nil {
+		return false
+	}
+
+	recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+	route.proxy.ServeHTTP(recorder, r)
+
+	// keyed by route rather than path, so the number of keys is bounded by the config
+	p.mutex.Lock()
+	if _, found := p.stats[route.name()]; !found {
+		p.stats[route.name()] = make(map[int]int)
+	}
+	p.stats[route.name()][recorder.statusCode]++
+	p.mutex.Unlock()
+
+	p.log.Info(fmt.Sprintf("%s | %s | %d - Proxied To %s", r.Host, r.URL.Path, recorder.statusCode, route.target.String()))
+	return true
+}
+
+// Stats returns a copy of the status code counts for each route, keyed by its prefix or '*' for the global target
+func (p *Proxy) Stats() map[string]map[int]int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	result := make(map[string]map[int]int, len(p.stats))
+	for path, codes := range p.stats {
+		result[path] = make(map[int]int, len(codes))
+		for statusCode, count := range codes {
+			result[path][statusCode] = count
+		}
+	}
+	return result
+}
+
+// name returns the key of the route in the stats, its prefix or '*' for the global target
+func (r *proxyRoute) name() string {
+	if len(r.prefix) == 0 {
+		return "*"
+	}
+	return r.prefix
+}
+
+// match returns the route for the given path, or nil if there is none. Prefixes match whole segments, so '/api' never matches '/apiv2'
+func (p *Proxy) match(path string) *proxyRoute {
+	for _, route := range p.routes {
+		prefix := strings.TrimSuffix(route.prefix, "/")
+		if len(route.prefix) == 0 || path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return route
+		}
+	}
+	return nil
+}
+
+// statusRecorder captures the status code written to an http.ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+// WriteHeader records the status code before writing it
+func (sr *statusRecorder) WriteHeader(statusCode int) {
+	sr.statusCode = statusCode
+	sr.ResponseWriter.WriteHeader(statusCode)
+}
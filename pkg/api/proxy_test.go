@@ -0,0 +1,151 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+// testUpstream returns a server responding with the given status code, and its name and the path requested in the body
+func testUpstream(name string, statusCode int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream", name)
+		w.WriteHeader(statusCode)
+		fmt.Fprintf(w, "%s %s %s", name, r.URL.Path, r.Header.Get("X-Test"))
+	}))
+}
+
+// TestProxyForward1 ensures unmatched requests are forwarded to the route with the longest prefix, then the global target
+func TestProxyForward1(t *testing.T) {
+	global, v2, v2Admin := testUpstream("global", http.StatusOK), testUpstream("v2", http.StatusCreated), testUpstream("admin", http.StatusForbidden)
+	defer global.Close()
+	defer v2.Close()
+	defer v2Admin.Close()
+
+	cfg := &config.Config{
+		Version:   2.0,
+		Endpoints: map[string]map[string]*config.Endpoint{"/api/v2/stubbed": {"get": {Response: http.StatusTeapot}}},
+		Proxy: &config.Proxy{
+			Target: global.URL,
+			Routes: map[string]string{"/api/v2/": v2.URL, "/api/v2/admin/": v2Admin.URL},
+		},
+	}
+	api := NewHTTPAPI(logger.NewLogger("std"), cfg, nil)
+
+	for _, entry := range []struct {
+		method, path string
+		statusCode   int
+		body         string
+	}{
+		{http.MethodGet, "/api/v2/stubbed", http.StatusTeapot, ""},
+		{http.MethodPost, "/api/v2/stubbed", http.StatusCreated, "v2 /api/v2/stubbed yes"},
+		{http.MethodGet, "/api/v2/items/1", http.StatusCreated, "v2 /api/v2/items/1 yes"},
+		{http.MethodGet, "/api/v2/items/2", http.StatusCreated, "v2 /api/v2/items/2 yes"},
+		{http.MethodGet, "/api/v2/admin/users", http.StatusForbidden, "admin /api/v2/admin/users yes"},
+		{http.MethodGet, "/other", http.StatusOK, "global /other yes"},
+	} {
+		r := httptest.NewRequest(entry.method, entry.path, nil)
+		r.Header.Set("X-Test", "yes")
+		w := httptest.NewRecorder()
+		api.mux.ServeHTTP(w, r)
+		if w.Code != entry.statusCode || w.Body.String() != entry.body {
+			t.Errorf("Unexpected Response For %s %s: %d %s", entry.method, entry.path, w.Code, w.Body.String())
+		}
+	}
+
+	_, proxy := api.current()
+	stats := proxy.Stats()
+	if len(stats) != 3 || stats["/api/v2/"][http.StatusCreated] != 3 || stats["/api/v2/admin/"][http.StatusForbidden] != 1 || stats["*"][http.StatusOK] != 1 {
+		t.Errorf("Unexpected Proxy Stats: %v", stats)
+	}
+}
+
+// TestProxyForward2 ensures requests matching no route are not forwarded when there is no global target
+func TestProxyForward2(t *testing.T) {
+	upstream := testUpstream("v2", http.StatusOK)
+	defer upstream.Close()
+
+	proxy, err := NewProxy(logger.NewLogger("std"), &config.Proxy{Routes: map[string]string{"/api/": upstream.URL}})
+	if err != nil {
+		t.Fatalf("Unable To Create Proxy: %s", err.Error())
+	}
+	if proxy.Forward(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/other", nil)) {
+		t.Errorf("Request Matching No Route Was Forwarded")
+	}
+	if !proxy.Forward(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/a", nil)) {
+		t.Errorf("Request Matching A Route Was Not Forwarded")
+	}
+}
+
+// TestGetEndpointEntry1 ensures a method missing from one matching URL falls through to other URLs, returning a 405 when none define it
+func TestGetEndpointEntry1(t *testing.T) {
+	cfg := &config.Config{Endpoints: map[string]map[string]*config.Endpoint{
+		"/a/:id":   {"get": {Response: http.StatusOK}},
+		"/:name/b": {"post": {Response: http.StatusCreated}},
+		"/exact":   {"get": {Response: http.StatusOK}},
+		"/:any":    {"post": {Response: http.StatusCreated}},
+	}}
+
+	for _, entry := range []struct {
+		method, path, url string
+		statusCode        int
+	}{
+		{http.MethodGet, "/a/b", "/a/:id", 0},
+		{http.MethodPost, "/a/b", "/:name/b", 0},
+		{http.MethodPost, "/a/c", "", http.StatusMethodNotAllowed},
+		{http.MethodDelete, "/a/b", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/missing/path", "", http.StatusNotFound},
+		// an exact URL is matched before any params, so its missing method is not looked for elsewhere
+		{http.MethodPost, "/exact", "", http.StatusMethodNotAllowed},
+	} {
+		// URLs are checked in map order, so each request is repeated to cover different orders
+		for i := 0; i < 20; i++ {
+			url, _, err := getEndpointEntry(cfg, httptest.NewRequest(entry.method, entry.path, nil))
+			statusCode := 0
+			if err != nil {
+				statusCode = err.StatusCode()
+			}
+			if url != entry.url || statusCode != entry.statusCode {
+				t.Errorf("Unexpected Entry For %s %s: %s %d", entry.method, entry.path, url, statusCode)
+				break
+			}
+		}
+	}
+}
+
+// TestProxyForward3 ensures route prefixes match whole path segments, and only requests which are not forwarded count as unmatched
+func TestProxyForward3(t *testing.T) {
+	upstream := testUpstream("api", http.StatusOK)
+	defer upstream.Close()
+
+	cfg := &config.Config{Version: 2.0, Proxy: &config.Proxy{Routes: map[string]string{"/api": upstream.URL}}}
+	api := NewHTTPAPI(logger.NewLogger("std"), cfg, nil)
+
+	for _, entry := range []struct {
+		path       string
+		statusCode int
+	}{
+		{"/api", http.StatusOK},
+		{"/api/items", http.StatusOK},
+		{"/apiv2/items", http.StatusNotFound},
+		{"/apifoo", http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, entry.path, nil))
+		if w.Code != entry.statusCode {
+			t.Errorf("Unexpected Status Code For %s: %d", entry.path, w.Code)
+		}
+	}
+
+	if unmatched := api.stats.Snapshot().Unmatched; unmatched["get"] != 2 {
+		t.Errorf("Unexpected Unmatched Stats: %v", unmatched)
+	}
+	if text := string(api.metrics.Text()); !strings.Contains(text, `ministub_unmatched_requests_total{method="GET"} 2`) {
+		t.Errorf("Unexpected Unmatched Metrics: %s", text)
+	}
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/convert"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// recordFlushInterval is how often the recorded config is rewritten whilst new exchanges are being captured
+const recordFlushInterval = time.Second
+
+// responseBuffer captures a response so it can be both recorded and returned, Write is promoted from the buffer
+type responseBuffer struct {
+	*bytes.Buffer
+	header     http.Header
+	statusCode int
+}
+
+// newResponseBuffer creates a new instance of responseBuffer
+func newResponseBuffer() *responseBuffer {
+	return &responseBuffer{Buffer: new(bytes.Buffer), header: make(http.Header), statusCode: http.StatusOK}
+}
+
+// Header returns the headers of the captured response
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+// WriteHeader records the status code of the captured response
+func (b *responseBuffer) WriteHeader(statusCode int) {
+	b.statusCode = statusCode
+}
+
+/*Recorder proxies all traffic to an upstream service, capturing each exchange into a ministub config file. The config is
+written by Flush, at most once per recordFlushInterval whilst serving, and once more when the recorder is shut down */
+type Recorder struct {
+	log          logger.Logger
+	proxy        *httputil.ReverseProxy
+	outPath      string
+	maxExchanges int
+	matchHeaders bool
+	exchanges    []*convert.Exchange
+	dirty        bool // exchanges have been captured since the config was last written
+	mutex        sync.Mutex
+	writeMutex   sync.Mutex
+}
+
+/*NewRecorder creates a new instance of Recorder writing the config generated from at most maxExchanges exchanges to outPath.
+Request headers are only required by the generated endpoints when matchHeaders is set */
+func NewRecorder(log logger.Logger, upstream, outPath string, maxExchanges int, matchHeaders bool) (*Recorder, error) {
+	if log == nil || len(outPath) == 0 || maxExchanges <= 0 {
+		return nil, fmt.Errorf("Invalid Args")
+	}
+
+	target, err := url.Parse(upstream)
+	if err != nil || len(target.Scheme) == 0 || len(target.Host) == 0 {
+		return nil, fmt.Errorf("Upstream %s Is Not An Absolute URL", upstream)
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Host = target.Host
+		// let the transport negotiate compression so recorded bodies are plain
+		r.Header.Del("Accept-Encoding")
+	}
+
+	return &Recorder{
+		log:          log,
+		proxy:        proxy,
+		outPath:      outPath,
+		maxExchanges: maxExchanges,
+		matchHeaders: matchHeaders,
+		exchanges:    make([]*convert.Exchange, 0),
+	}, nil
+}
+
+// ListenAndServe begins the recorder listening for requests, writing the config whilst new exchanges are captured
+func (rec *Recorder) ListenAndServe(addressBind string, port int) error {
+	rec.log.Info(fmt.Sprintf("Beginning Recording HTTP Requests On %s:%d To %s", addressBind, port, rec.outPath))
+
+	go func() {
+		ticker := time.NewTicker(recordFlushInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := rec.Flush(); err != nil {
+				rec.log.Error(fmt.Sprintf("Unable To Write Recording: %s", err.Error()))
+			}
+		}
+	}()
+
+	return http.ListenAndServe(fmt.Sprintf("%s:%d", addressBind, port), rec)
+}
+
+// ServeHTTP forwards the request upstream and records the exchange, exchanges beyond maxExchanges are forwarded but not recorded
+func (rec *Recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	exchange := &convert.Exchange{
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Query:   r.URL.Query(),
+		Headers: r.Header.Clone(),
+		Body:    body,
+	}
+
+	// the upstream response is buffered so it can be both recorded and returned
+	capture := newResponseBuffer()
+	rec.proxy.ServeHTTP(capture, r)
+
+	for name, values := range capture.Header() {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(capture.statusCode)
+	w.Write(capture.Bytes())
+
+	exchange.StatusCode = capture.statusCode
+	exchange.ResponseHeaders = capture.Header().Clone()
+	exchange.ResponseBody = capture.Bytes()
+
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	if len(rec.exchanges) >= rec.maxExchanges {
+		rec.log.Info(fmt.Sprintf("%s | %s %s | %d - Not Recorded, %d Exchanges Already Recorded", r.Host, r.Method, r.URL.Path, capture.statusCode, rec.maxExchanges))
+		return
+	}
+	rec.exchanges = append(rec.exchanges, exchange)
+	rec.dirty = true
+	rec.log.Info(fmt.Sprintf("%s | %s %s | %d - Recorded", r.Host, r.Method, r.URL.Path, capture.statusCode))
+}
+
+/*Flush generates a config from every exchange recorded and writes it to the output path, it does nothing when no exchange
+has been recorded since the last write. Endpoints which fail validation are logged and left out so the rest are still written */
+func (rec *Recorder) Flush() error {
+	rec.writeMutex.Lock()
+	defer rec.writeMutex.Unlock()
+
+	rec.mutex.Lock()
+	if !rec.dirty {
+		rec.mutex.Unlock()
+		return nil
+	}
+	exchanges := append([]*convert.Exchange{}, rec.exchanges...)
+	rec.dirty = false
+	rec.mutex.Unlock()
+
+	if err := rec.write(exchanges); err != nil {
+		rec.mutex.Lock()
+		rec.dirty = true
+		rec.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// write generates a config from the given exchanges, leaving out invalid endpoints, and writes it to the output path
+func (rec *Recorder) write(exchanges []*convert.Exchange) error {
+	cfg := &config.Config{Version: 1.0, Endpoints: convert.BuildEndpoints(exchanges, &convert.EndpointOptions{CollapseIDs: true, MatchHeaders: rec.matchHeaders})}
+
+	urls := make([]string, 0, len(cfg.Endpoints))
+	for url := range cfg.Endpoints {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	for _, url := range urls {
+		for method, entry := range cfg.Endpoints[url] {
+			if err := config.ValidateEndpoint(cfg, url, method, entry); err != nil {
+				rec.log.Error(fmt.Sprintf("Recorded Endpoint %s %s Is Invalid And Was Not Written: %s", method, url, err.Error()))
+				delete(cfg.Endpoints[url], method)
+			}
+		}
+		if len(cfg.Endpoints[url]) == 0 {
+			delete(cfg.Endpoints, url)
+		}
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("Unable To Marshal Config: %s", err.Error())
+	}
+
+	return ioutil.WriteFile(rec.outPath, data, 0644)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// TestRecorder1 ensures exchanges are forwarded and returned as is, and only written once flushed
+func TestRecorder1(t *testing.T) {
+	upstream := testUpstream("upstream", http.StatusCreated)
+	defer upstream.Close()
+
+	dir, err := ioutil.TempDir("", "ministub")
+	if err != nil {
+		t.Fatalf("Unable To Create Temp Dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+	outPath := filepath.Join(dir, "recorded.yml")
+
+	rec, err := NewRecorder(logger.NewLogger("std"), upstream.URL, outPath, 2, false)
+	if err != nil {
+		t.Fatalf("Unexpected Error Creating Recorder: %s", err.Error())
+	}
+
+	for _, path := range []string{"/items/1", "/items/2", "/other"} {
+		w := httptest.NewRecorder()
+		rec.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusCreated || w.Header().Get("X-Upstream") != "upstream" || !strings.HasPrefix(w.Body.String(), "upstream "+path) {
+			t.Errorf("Request To %s Was Not Returned As Is: %d %v %s", path, w.Code, w.Header(), w.Body.String())
+		}
+	}
+
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Errorf("Recording Was Written Before Being Flushed")
+	}
+	if err := rec.Flush(); err != nil {
+		t.Fatalf("Unexpected Error Flushing Recording: %s", err.Error())
+	}
+
+	data, err := ioutil.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("Unable To Read Recording: %s", err.Error())
+	}
+	cfg := new(config.Config)
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		t.Fatalf("Unable To Decode Recording: %s", err.Error())
+	}
+	if len(cfg.Endpoints) != 1 || cfg.Endpoints["/items/:item_id"]["get"] == nil {
+		t.Errorf("Recording Did Not Hold Only The First 2 Exchanges: %v", cfg.Endpoints)
+	}
+	if err := config.Validate(cfg); err != nil {
+		t.Errorf("Recording Is Invalid: %s", err.Error())
+	}
+
+	// a flush with nothing new recorded leaves the file alone
+	os.Remove(outPath)
+	if err := rec.Flush(); err != nil {
+		t.Fatalf("Unexpected Error Flushing Recording: %s", err.Error())
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Errorf("Recording Was Rewritten With Nothing New Recorded")
+	}
+}
+
+// TestNewRecorder1 ensures invalid arguments are returned as errors
+func TestNewRecorder1(t *testing.T) {
+	for _, tc := range []struct {
+		upstream     string
+		outPath      string
+		maxExchanges int
+	}{
+		{"http://localhost:9000", "", 10},
+		{"http://localhost:9000", "out.yml", 0},
+		{"localhost:9000", "out.yml", 10},
+		{"/relative", "out.yml", 10},
+	} {
+		if _, err := NewRecorder(logger.NewLogger("std"), tc.upstream, tc.outPath, tc.maxExchanges, false); err == nil {
+			t.Errorf("Invalid Args %+v Returned No Error", tc)
+		}
+	}
+}
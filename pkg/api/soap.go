@@ -0,0 +1,69 @@
+package api
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// soapContentType is the default Content-Type for SOAP responses
+const soapContentType = "text/xml; charset=utf-8"
+
+// getSOAPOperation returns the name and definition of the SOAP operation requested, matched on the SOAPAction header then the first element of the SOAP body
+func getSOAPOperation(operations map[string]*config.Endpoint, doc *XMLNode, r *http.Request) (string, *config.Endpoint, *HTTPError) {
+	if action := strings.Trim(r.Header.Get("SOAPAction"), `"`); len(action) > 0 {
+		if entry, found := operations[action]; found {
+			return action, entry, nil
+		}
+	}
+
+	// SOAP 1.2 carries the action as a Content-Type parameter
+	if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil {
+		if entry, found := operations[params["action"]]; found {
+			return params["action"], entry, nil
+		}
+	}
+
+	if doc != nil {
+		if body := doc.Child("Body"); body != nil && len(body.Children) > 0 {
+			if entry, found := operations[body.Children[0].Name]; found {
+				return body.Children[0].Name, entry, nil
+			}
+		}
+	}
+
+	return "", nil, &HTTPError{"SOAP Operation Not Found", http.StatusNotFound}
+}
+
+// soapFaultEnvelope generates a SOAP 1.1 envelope holding the given fault
+func soapFaultEnvelope(fault *config.Fault) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>`)
+	xml.EscapeText(buf, []byte(fault.Code))
+	buf.WriteString("</faultcode><faultstring>")
+	xml.EscapeText(buf, []byte(fault.String))
+	buf.WriteString("</faultstring>")
+	if len(fault.Detail) > 0 {
+		buf.WriteString(fmt.Sprintf("<detail>%s</detail>", fault.Detail))
+	}
+	buf.WriteString("</soap:Fault></soap:Body></soap:Envelope>")
+	return buf.Bytes()
+}
+
+// setupSOAPFaultResponse generates a SOAP fault from the given error ready for immediate return
+func (api *HTTPAPI) setupSOAPFaultResponse(err *HTTPError, w http.ResponseWriter) {
+	code := "soap:Server"
+	if err.StatusCode() < http.StatusInternalServerError {
+		code = "soap:Client"
+	}
+
+	w.Header().Set("Content-Type", soapContentType)
+	w.WriteHeader(err.StatusCode())
+	w.Write(soapFaultEnvelope(&config.Fault{Code: code, String: err.Error()}))
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+// soapRequestBody wraps the given body in a SOAP 1.1 envelope
+func soapRequestBody(body string) string {
+	return `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` + body + `</soap:Body></soap:Envelope>`
+}
+
+// testSOAPAPI returns an API serving a SOAP endpoint with an operation matched by SOAPAction, one by body element and one faulting
+func testSOAPAPI() *HTTPAPI {
+	cfg := &config.Config{
+		Version: 2.0,
+		Endpoints: map[string]map[string]*config.Endpoint{"/soap": {"post": {SOAP: map[string]*config.Endpoint{
+			"urn:payments/Authorise": {
+				Recieves:  &config.Recieves{XPath: map[string]string{"//Amount": "float"}},
+				Responses: map[int]*config.Response{200: {RawBody: "<AuthoriseResponse/>", Weight: 100}},
+			},
+			"Refund": {Responses: map[int]*config.Response{200: {RawBody: "<RefundResponse/>", Weight: 100}}},
+			"Void": {Responses: map[int]*config.Response{500: {
+				Fault:  &config.Fault{Code: "soap:Server", String: "Declined <05>", Detail: "<Code>05</Code>"},
+				Weight: 100,
+			}}},
+		}}}},
+	}
+	return NewHTTPAPI(logger.NewLogger("std"), cfg, nil)
+}
+
+// TestSOAPRequest1 ensures operations are dispatched on the SOAPAction header, then on the first element of the body
+func TestSOAPRequest1(t *testing.T) {
+	api := testSOAPAPI()
+
+	for _, entry := range []struct {
+		action, body string
+		statusCode   int
+		contains     string
+	}{
+		{`"urn:payments/Authorise"`, "<Authorise><Amount>1.50</Amount></Authorise>", http.StatusOK, "<AuthoriseResponse/>"},
+		{"", "<Refund><Reference>abc</Reference></Refund>", http.StatusOK, "<RefundResponse/>"},
+		{"urn:unknown", "<Refund/>", http.StatusOK, "<RefundResponse/>"},
+		{"", "<Unknown/>", http.StatusNotFound, "<faultcode>soap:Client</faultcode><faultstring>SOAP Operation Not Found</faultstring>"},
+		{"urn:payments/Authorise", "<Authorise><Amount>lots</Amount></Authorise>", http.StatusBadRequest, "<faultcode>soap:Client</faultcode>"},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/soap", strings.NewReader(soapRequestBody(entry.body)))
+		if len(entry.action) > 0 {
+			r.Header.Set("SOAPAction", entry.action)
+		}
+		w := httptest.NewRecorder()
+		api.mux.ServeHTTP(w, r)
+
+		if w.Code != entry.statusCode || !strings.Contains(w.Body.String(), entry.contains) {
+			t.Errorf("Unexpected Response For %s %s: %d %s", entry.action, entry.body, w.Code, w.Body.String())
+		}
+		if w.Header().Get("Content-Type") != soapContentType {
+			t.Errorf("Unexpected Content-Type For %s %s: %s", entry.action, entry.body, w.Header().Get("Content-Type"))
+		}
+	}
+
+	stats := api.stats.Snapshot()
+	if stats.Endpoints["/soap#urn:payments/Authorise"]["post"].StatusCodes[http.StatusOK] != 1 || stats.Endpoints["/soap#Refund"]["post"].StatusCodes[http.StatusOK] != 2 {
+		t.Errorf("Unexpected Stats: %+v", stats.Endpoints)
+	}
+}
+
+// TestSOAPRequest2 ensures a configured fault is returned as an escaped SOAP fault envelope, with its detail as is
+func TestSOAPRequest2(t *testing.T) {
+	api := testSOAPAPI()
+
+	r := httptest.NewRequest(http.MethodPost, "/soap", strings.NewReader(soapRequestBody("<Void/>")))
+	r.Header.Set("Content-Type", `application/soap+xml; charset=utf-8; action="Void"`)
+	w := httptest.NewRecorder()
+	api.mux.ServeHTTP(w, r)
+
+	expected := "<soap:Fault><faultcode>soap:Server</faultcode><faultstring>Declined &lt;05&gt;</faultstring><detail><Code>05</Code></detail></soap:Fault>"
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), expected) {
+		t.Errorf("Unexpected Fault: %d %s", w.Code, w.Body.String())
+	}
+	if _, err := ParseXML(w.Body.Bytes()); err != nil {
+		t.Errorf("Fault Is Not Valid XML: %s", err.Error())
+	}
+}
@@ -0,0 +1,301 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+// maxPacketSize is the largest raw chunk or UDP datagram read in a single call
+const maxPacketSize = 65535
+
+// socketRule is a compiled config.ListenerRule
+type socketRule struct {
+	index int // position of the rule within the listener, rules sharing a pattern are counted separately
+	def   *config.ListenerRule
+	regex *regexp.Regexp
+	hex   []int
+	reply []byte
+}
+
+// SocketAPI represents a raw TCP or UDP stub listener
+type SocketAPI struct {
+	name      string
+	log       logger.Logger
+	cfg       *config.Config
+	def       *config.Listener
+	rules     []*socketRule
+	req       Requester
+	stats     []int // match count of each rule, by index
+	unmatched int
+	mutex     sync.Mutex
+
+	observe func(results []*ActionResult) // records action outcomes with the HTTP API the listener is added to, nil until it is added
+}
+
+// NewSocketAPI creates a new instance of SocketAPI for the listener with the given name
+func NewSocketAPI(log logger.Logger, cfg *config.Config, name string, req Requester) (*SocketAPI, error) {
+	if log == nil || cfg == nil {
+		return nil, fmt.Errorf("Invalid Args")
+	}
+
+	def, found := cfg.Listeners[name]
+	if !found {
+		return nil, fmt.Errorf("Listener %s Not Defined", name)
+	}
+
+	api := &SocketAPI{
+		name:  name,
+		log:   log,
+		cfg:   cfg,
+		def:   def,
+		rules: make([]*socketRule, 0, len(def.Rules)),
+		req:   req,
+		stats: make([]int, len(def.Rules)),
+	}
+
+	for i, ruleDef := range def.Rules {
+		rule, err := compileSocketRule(ruleDef, def.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("Listener %s: %s", name, err.Error())
+		}
+		rule.index = i
+		api.rules = append(api.rules, rule)
+	}
+
+	return api, nil
+}
+
+// Name returns the config name of this listener
+func (api *SocketAPI) Name() string { return api.name }
+
+// Stats returns a copy of the match counts for each rule of this listener, keyed by '{index}: {pattern}'
+func (api *SocketAPI) Stats() map[string]int {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	result := map[string]int{"unmatched": api.unmatched}
+	for _, rule := range api.rules {
+		result[fmt.Sprintf("%d: %s", rule.index, rule.def.Pattern())] = api.stats[rule.index]
+	}
+	return result
+}
+
+// ListenAndServe begins the listener accepting connections or datagrams
+func (api *SocketAPI) ListenAndServe(addressBind string) error {
+	address := fmt.Sprintf("%s:%d", addressBind, api.def.Port)
+	api.log.Info(fmt.Sprintf("Beginning Listening For %s Data On %s For Listener %s", strings.ToUpper(api.def.Protocol), address, api.name))
+
+	switch {
+	case api.def.Protocol == "tcp":
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return err
+		}
+		return api.serveConns(listener)
+	case api.def.Protocol == "udp":
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return err
+		}
+		return api.servePackets(conn)
+	default:
+		return fmt.Errorf("Protocol %s Not Supported", api.def.Protocol)
+	}
+}
+
+// serveConns accepts TCP connections, serving each in its own goroutine
+func (api *SocketAPI) serveConns(listener net.Listener) error {
+	defer listener.Close()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go api.handleConn(conn)
+	}
+}
+
+// handleConn serves a single TCP connection until either side closes it
+func (api *SocketAPI) handleConn(conn net.Conn) {
+	defer conn.Close()
+	remote := conn.RemoteAddr().String()
+
+	if len(api.def.Greeting) > 0 {
+		if _, err := conn.Write(lineTerminate([]byte(api.def.Greeting), api.def.Mode)); err != nil {
+			api.log.Error(fmt.Sprintf("%s | %s | Unable To Write Greeting: %s", api.name, remote, err.Error()))
+			return
+		}
+	}
+
+	if api.def.Mode == "raw" {
+		buf := make([]byte, maxPacketSize)
+		for {
+			n, err := conn.Read(buf)
+			if n > 0 && !api.reply(conn, buf[:n], remote) {
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		if !api.reply(conn, []byte(strings.TrimSuffix(scanner.Text(), "\r")), remote) {
+			return
+		}
+	}
+}
+
+// servePackets serves UDP datagrams, each datagram is matched as a single message
+func (api *SocketAPI) servePackets(conn net.PacketConn) error {
+	defer conn.Close()
+	buf := make([]byte, maxPacketSize)
+
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+
+		data := make([]byte, n)
+		copy(data, buf[:n])
+		if api.def.Mode != "raw" {
+			data = []byte(strings.TrimRight(string(data), "\r\n"))
+		}
+
+		go func(addr net.Addr) {
+			if reply, _ := api.handleData(data, addr.String()); len(reply) > 0 {
+				if _, err := conn.WriteTo(reply, addr); err != nil {
+					api.log.Error(fmt.Sprintf("%s | %s | Unable To Write Reply: %s", api.name, addr.String(), err.Error()))
+				}
+			}
+		}(addr)
+	}
+}
+
+// reply handles a single message from a TCP connection and writes the scripted reply, returns false when the connection should close
+func (api *SocketAPI) reply(w io.Writer, data []byte, remote string) bool {
+	reply, keepOpen := api.handleData(data, remote)
+	if len(reply) > 0 {
+		if _, err := w.Write(reply); err != nil {
+			api.log.Error(fmt.Sprintf("%s | %s | Unable To Write Reply: %s", api.name, remote, err.Error()))
+			return false
+		}
+	}
+	return keepOpen
+}
+
+// handleData matches a single message against the rules, returning the scripted reply and whether the connection should stay open
+func (api *SocketAPI) handleData(data []byte, remote string) ([]byte, bool) {
+	rule := api.matchRule(data)
+	if rule == nil {
+		api.mutex.Lock()
+		api.unmatched++
+		api.mutex.Unlock()
+		api.log.Error(fmt.Sprintf("%s | %s | No Rule Matched %d Bytes", api.name, remote, len(data)))
+		return nil, true
+	}
+
+	pattern := rule.def.Pattern()
+	api.mutex.Lock()
+	api.stats[rule.index]++
+	api.mutex.Unlock()
+
+	if rule.def.DelayMs > 0 {
+		time.Sleep(time.Duration(rule.def.DelayMs) * time.Millisecond)
+	}
+
+	if len(rule.def.Actions) > 0 {
+		go func() {
+			results := ExecuteActions(rule.def.Actions, fmt.Sprintf("%s %s", api.name, pattern), api.cfg, api.log, api.req)
+			if api.observe != nil {
+				api.observe(results)
+			}
+		}()
+	}
+
+	api.log.Info(fmt.Sprintf("%s | %s | %s", api.name, remote, pattern))
+	return rule.reply, !rule.def.Close
+}
+
+// matchRule returns the first rule matching the given data, or nil if there is none
+func (api *SocketAPI) matchRule(data []byte) *socketRule {
+	for _, rule := range api.rules {
+		switch {
+		case rule.regex != nil:
+			if rule.regex.Match(data) {
+				return rule
+			}
+		case rule.hex != nil:
+			if containsHexPattern(data, rule.hex) {
+				return rule
+			}
+		default:
+			return rule
+		}
+	}
+	return nil
+}
+
+// lineTerminate appends a newline to the given data when in line mode and one is not already present
+func lineTerminate(data []byte, mode string) []byte {
+	if mode == "raw" || (len(data) > 0 && data[len(data)-1] == '\n') {
+		return data
+	}
+	return append(data, '\n')
+}
+
+// compileSocketRule prepares a config.ListenerRule for matching in the given listener mode
+func compileSocketRule(def *config.ListenerRule, mode string) (rule *socketRule, err error) {
+	rule = &socketRule{def: def}
+
+	switch {
+	case len(def.Reply) > 0:
+		rule.reply = lineTerminate([]byte(def.Reply), mode)
+	case len(def.ReplyHex) > 0:
+		if rule.reply, err = config.DecodeHex(def.ReplyHex); err != nil {
+			return nil, fmt.Errorf("Invalid replyHex %s: %s", def.ReplyHex, err.Error())
+		}
+	}
+
+	if len(def.Match) > 0 {
+		if rule.regex, err = regexp.Compile(def.Match); err != nil {
+			return nil, fmt.Errorf("Invalid Regex %s: %s", def.Match, err.Error())
+		}
+	}
+	if len(def.MatchHex) > 0 {
+		if rule.hex, err = config.ParseHexPattern(def.MatchHex); err != nil {
+			return nil, err
+		}
+	}
+
+	return rule, nil
+}
+
+// containsHexPattern returns whether the given data contains the hex pattern, where -1 matches any byte
+func containsHexPattern(data []byte, pattern []int) bool {
+	for start := 0; start+len(pattern) <= len(data); start++ {
+		matched := true
+		for i, b := range pattern {
+			if b != -1 && int(data[start+i]) != b {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
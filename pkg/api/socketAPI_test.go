@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"github.com/MichaelWittgreffe/ministub/pkg/logger"
+)
+
+// testSocketAPI returns a listener with the given rules, whose actions send requests to a mock requester
+func testSocketAPI(t *testing.T, protocol, mode string, rules []*config.ListenerRule) *SocketAPI {
+	cfg := &config.Config{
+		Services:  map[string]*config.Service{"api": {Hostname: "localhost", Port: 8080}},
+		Requests:  map[string]*config.Request{"ping": {URL: "/ping", Method: "get"}},
+		Listeners: map[string]*config.Listener{"test": {Protocol: protocol, Port: 7000, Mode: mode, Greeting: "HELLO", Rules: rules}},
+	}
+	api, err := NewSocketAPI(logger.NewLogger("std"), cfg, "test", new(mockRequester))
+	if err != nil {
+		t.Fatalf("Unable To Create Listener: %s", err.Error())
+	}
+	return api
+}
+
+// TestHandleData1 ensures messages are matched by the first rule in order, and rules sharing a pattern are counted separately
+func TestHandleData1(t *testing.T) {
+	api := testSocketAPI(t, "tcp", "line", []*config.ListenerRule{
+		{Match: "^PING", Reply: "PONG"},
+		{MatchHex: "de ad ?? ef", ReplyHex: "00 01", Close: true},
+		{Match: "^PING", Reply: "NEVER"},
+	})
+
+	for _, entry := range []struct {
+		data     []byte
+		reply    string
+		keepOpen bool
+	}{
+		{[]byte("PING 1"), "PONG\n", true},
+		{[]byte{0x01, 0xde, 0xad, 0x00, 0xef}, "\x00\x01", false},
+		{[]byte{0xde, 0xad, 0x00}, "", true},
+		{[]byte("QUIT"), "", true},
+	} {
+		reply, keepOpen := api.handleData(entry.data, "test")
+		if string(reply) != entry.reply || keepOpen != entry.keepOpen {
+			t.Errorf("Unexpected Reply To %q: %q %t", entry.data, reply, keepOpen)
+		}
+	}
+
+	stats := api.Stats()
+	if stats["0: ^PING"] != 1 || stats["1: hex:de ad ?? ef"] != 1 || stats["2: ^PING"] != 0 || stats["unmatched"] != 2 {
+		t.Errorf("Unexpected Stats: %v", stats)
+	}
+}
+
+// signalRequester signals each request made on a channel
+type signalRequester chan *config.Request
+
+// Request signals the request, which always succeeds
+func (s signalRequester) Request(tgt *config.Service, req *config.Request) error {
+	s <- req
+	return nil
+}
+
+// TestHandleData2 ensures the actions of a matched rule are run, with or without an HTTP API to record them
+func TestHandleData2(t *testing.T) {
+	rules := []*config.ListenerRule{{Reply: "OK", Actions: []*config.Action{{Request: &config.RequestAction{Target: "api", ID: "ping"}}}}}
+
+	// not added to an HTTP API, so nothing observes the actions
+	api := testSocketAPI(t, "tcp", "line", rules)
+	requests := make(signalRequester, 1)
+	api.req = requests
+	api.handleData([]byte("first"), "test")
+	select {
+	case <-requests:
+	case <-time.After(time.Second):
+		t.Errorf("Actions Not Run Without An HTTP API")
+	}
+
+	api = testSocketAPI(t, "tcp", "line", rules)
+	results := make(chan []*ActionResult, 1)
+	api.observe = func(found []*ActionResult) { results <- found }
+	api.handleData([]byte("second"), "test")
+
+	select {
+	case found := <-results:
+		if len(found) != 1 || !found[0].Success || found[0].Request != "ping" {
+			t.Errorf("Unexpected Action Results: %+v", found)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Actions Not Run")
+	}
+}
+
+// TestSocketAPI1 ensures a TCP client is greeted, replied to line by line, and disconnected by a closing rule
+func TestSocketAPI1(t *testing.T) {
+	api := testSocketAPI(t, "tcp", "line", []*config.ListenerRule{
+		{Match: "^PING", Reply: "PONG"},
+		{Match: "^QUIT", Reply: "BYE", Close: true},
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable To Listen: %s", err.Error())
+	}
+	defer listener.Close()
+	go api.serveConns(listener)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Unable To Connect: %s", err.Error())
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Second))
+	reader := bufio.NewReader(conn)
+
+	for _, entry := range []struct{ send, expected string }{{"", "HELLO\n"}, {"PING\r\n", "PONG\n"}, {"QUIT\n", "BYE\n"}} {
+		if len(entry.send) > 0 {
+			conn.Write([]byte(entry.send))
+		}
+		if line, err := reader.ReadString('\n'); err != nil || line != entry.expected {
+			t.Errorf("Unexpected Reply To %q: %q %v", entry.send, line, err)
+		}
+	}
+
+	if _, err := reader.ReadByte(); err == nil {
+		t.Errorf("Connection Not Closed After Closing Rule")
+	}
+}
+
+// TestSocketAPI2 ensures each UDP datagram is matched and replied to the sender
+func TestSocketAPI2(t *testing.T) {
+	api := testSocketAPI(t, "udp", "raw", []*config.ListenerRule{{MatchHex: "ca fe", ReplyHex: "be ef"}})
+
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable To Listen: %s", err.Error())
+	}
+	go api.servePackets(server)
+	defer server.Close()
+
+	conn, err := net.Dial("udp", server.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Unable To Connect: %s", err.Error())
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Second))
+
+	conn.Write([]byte{0x00, 0xca, 0xfe})
+	buf := make([]byte, 16)
+	if n, err := conn.Read(buf); err != nil || string(buf[:n]) != "\xbe\xef" {
+		t.Errorf("Unexpected Reply: %x %v", buf[:n], err)
+	}
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// latencySamples is the number of most recent response times kept for each endpoint, percentiles are taken from these
+const latencySamples = 1000
+
+// endpointStats holds the counts of a single endpoint and method
+type endpointStats struct {
+	statusCodes map[int]int
+	rejected    map[string]int // reason -> count
+	latencies   []time.Duration
+	next        int // index the next latency is written to once latencies is full
+}
+
+// RequestStats counts the outcomes of a request sent by a follow-on action
+type RequestStats struct {
+	Success int `json:"success"`
+	Failure int `json:"failure"`
+}
+
+// LatencyStats summarises the response times of an endpoint in milliseconds
+type LatencyStats struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// EndpointStats is a snapshot of the counts of a single endpoint and method
+type EndpointStats struct {
+	StatusCodes map[int]int    `json:"statusCodes"`
+	Rejected    map[string]int `json:"rejected,omitempty"` // reason -> count
+	LatencyMs   *LatencyStats  `json:"latencyMs,omitempty"`
+}
+
+// StatsSnapshot is a copy of every count held by Stats at a single point in time
+type StatsSnapshot struct {
+	Endpoints map[string]map[string]*EndpointStats `json:"endpoints"` // url -> method: stats
+	Unmatched map[string]int                       `json:"unmatched"` // method -> count
+	Rejected  map[string]int                       `json:"rejected"`  // reason -> count, including requests matching no endpoint
+	Requests  map[string]*RequestStats             `json:"requests"`  // request ID -> outcomes
+}
+
+/*Stats counts the requests served by each endpoint and the requests sent by follow-on actions. Every method is safe to call
+from concurrent requests. Endpoints are keyed by their URL as defined in the config, with any SOAP operation after a '#' */
+type Stats struct {
+	mutex     sync.Mutex
+	endpoints map[string]map[string]*endpointStats // url -> method: stats
+	unmatched map[string]int
+	rejected  map[string]int
+	requests  map[string]*RequestStats
+}
+
+// NewStats creates a new instance of Stats
+func NewStats() *Stats {
+	s := new(Stats)
+	s.Reset()
+	return s
+}
+
+// Reset removes every count
+func (s *Stats) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.endpoints = make(map[string]map[string]*endpointStats)
+	s.unmatched = make(map[string]int)
+	s.rejected = make(map[string]int)
+	s.requests = make(map[string]*RequestStats)
+}
+
+// Retain removes the counts of every endpoint the given function returns false for, other counts are kept
+func (s *Stats) Retain(keep func(url string) bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for url := range s.endpoints {
+		if !keep(url) {
+			delete(s.endpoints, url)
+		}
+	}
+}
+
+// endpoint returns the stats of an endpoint, adding them on first use. The caller must hold the mutex
+func (s *Stats) endpoint(url, method string) *endpointStats {
+	method = strings.ToLower(method)
+	if s.endpoints[url] == nil {
+		s.endpoints[url] = make(map[string]*endpointStats)
+	}
+	stats, found := s.endpoints[url][method]
+	if !found {
+		stats = &endpointStats{statusCodes: make(map[int]int), rejected: make(map[string]int)}
+		s.endpoints[url][method] = stats
+	}
+	return stats
+}
+
+// AddEndpoint adds an endpoint with zero counts for each of its responses, so status codes never returned are still listed
+func (s *Stats) AddEndpoint(url, method string, responses map[int]*config.Response) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := s.endpoint(url, method)
+	for statusCode := range responses {
+		if _, found := stats.statusCodes[statusCode]; !found {
+			stats.statusCodes[statusCode] = 0
+		}
+	}
+}
+
+// ObserveRequest records a request served by an endpoint, along with the time taken to respond
+func (s *Stats) ObserveRequest(url, method string, statusCode int, duration time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := s.endpoint(url, method)
+	stats.statusCodes[statusCode]++
+	if len(stats.latencies) < latencySamples {
+		stats.latencies = append(stats.latencies, duration)
+		return
+	}
+	stats.latencies[stats.next] = duration
+	stats.next = (stats.next + 1) % latencySamples
+}
+
+// ObserveUnmatched records a request matching no endpoint
+func (s *Stats) ObserveUnmatched(method string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.unmatched[strings.ToLower(method)]++
+}
+
+// ObserveRejected records a request rejected for the given reason, the URL is empty when the request matched no endpoint
+func (s *Stats) ObserveRejected(url, method, reason string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.rejected[reason]++
+	if len(url) > 0 {
+		s.endpoint(url, method).rejected[reason]++
+	}
+}
+
+// ObserveActions records the outcome of every request sent by the given actions
+func (s *Stats) ObserveActions(results []*ActionResult) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, result := range results {
+		if result.Action != "request" {
+			continue
+		}
+		stats, found := s.requests[result.Request]
+		if !found {
+			stats = new(RequestStats)
+			s.requests[result.Request] = stats
+		}
+		if result.Success {
+			stats.Success++
+		} else {
+			stats.Failure++
+		}
+	}
+}
+
+// Snapshot returns a copy of every count, which is not changed by requests served afterwards
+func (s *Stats) Snapshot() *StatsSnapshot {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshot := &StatsSnapshot{
+		Endpoints: make(map[string]map[string]*EndpointStats, len(s.endpoints)),
+		Unmatched: make(map[string]int, len(s.unmatched)),
+		Rejected:  make(map[string]int, len(s.rejected)),
+		Requests:  make(map[string]*RequestStats, len(s.requests)),
+	}
+	for url, methods := range s.endpoints {
+		snapshot.Endpoints[url] = make(map[string]*EndpointStats, len(methods))
+		for method, stats := range methods {
+			snapshot.Endpoints[url][method] = stats.snapshot()
+		}
+	}
+	for method, count := range s.unmatched {
+		snapshot.Unmatched[method] = count
+	}
+	for reason, count := range s.rejected {
+		snapshot.Rejected[reason] = count
+	}
+	for id, stats := range s.requests {
+		snapshot.Requests[id] = &RequestStats{Success: stats.Success, Failure: stats.Failure}
+	}
+	return snapshot
+}
+
+// snapshot returns a copy of the counts of an endpoint, the caller must hold the mutex
+func (e *endpointStats) snapshot() *EndpointStats {
+	snapshot := &EndpointStats{StatusCodes: make(map[int]int, len(e.statusCodes))}
+	for statusCode, count := range e.statusCodes {
+		snapshot.StatusCodes[statusCode] = count
+	}
+	if len(e.rejected) > 0 {
+		snapshot.Rejected = make(map[string]int, len(e.rejected))
+		for reason, count := range e.rejected {
+			snapshot.Rejected[reason] = count
+		}
+	}
+
+	if len(e.latencies) > 0 {
+		sorted := append([]time.Duration{}, e.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		snapshot.LatencyMs = &LatencyStats{
+			P50: percentile(sorted, 50),
+			P90: percentile(sorted, 90),
+			P99: percentile(sorted, 99),
+			Max: milliseconds(sorted[len(sorted)-1]),
+		}
+	}
+	return snapshot
+}
+
+// percentile returns the nearest-rank percentile of the given sorted durations in milliseconds
+func percentile(sorted []time.Duration, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return milliseconds(sorted[rank-1])
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// statsHandler returns a snapshot of the stats as JSON
+func (api *HTTPAPI) statsHandler(w http.ResponseWriter, r *http.Request) int {
+	return api.writeJSON(w, http.StatusOK, api.stats.Snapshot())
+}
+
+// resetStatsHandler removes every count from the stats
+func (api *HTTPAPI) resetStatsHandler(w http.ResponseWriter, r *http.Request) int {
+	api.stats.Reset()
+	w.WriteHeader(http.StatusNoContent)
+	return http.StatusNoContent
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// TestStats1 ensures counts are kept by endpoint and method, and a snapshot is not changed by later requests
+func TestStats1(t *testing.T) {
+	stats := NewStats()
+	stats.AddEndpoint("/a", "GET", map[int]*config.Response{200: {}, 500: {}})
+	for i := 1; i <= 100; i++ {
+		stats.ObserveRequest("/a", "GET", 200, time.Duration(i)*time.Millisecond)
+	}
+	stats.ObserveRequest("/a", "POST", 201, time.Millisecond)
+	stats.ObserveRejected("/a", "POST", "body")
+	stats.ObserveRejected("", "GET", "path_param")
+	stats.ObserveUnmatched("DELETE")
+	stats.ObserveActions([]*ActionResult{{Action: "delay", Success: true}, {Action: "request", Request: "ping", Success: true}, {Action: "request", Request: "ping"}})
+
+	snapshot := stats.Snapshot()
+	stats.ObserveRequest("/a", "GET", 200, time.Millisecond)
+
+	get := snapshot.Endpoints["/a"]["get"]
+	switch {
+	case get == nil || get.StatusCodes[200] != 100 || get.StatusCodes[500] != 0 || len(get.StatusCodes) != 2:
+		t.Errorf("Unexpected GET Stats: %+v", get)
+	case *get.LatencyMs != LatencyStats{P50: 50, P90: 90, P99: 99, Max: 100}:
+		t.Errorf("Unexpected GET Latency: %+v", get.LatencyMs)
+	case snapshot.Endpoints["/a"]["post"].StatusCodes[201] != 1 || snapshot.Endpoints["/a"]["post"].Rejected["body"] != 1:
+		t.Errorf("Unexpected POST Stats: %+v", snapshot.Endpoints["/a"]["post"])
+	case snapshot.Rejected["body"] != 1 || snapshot.Rejected["path_param"] != 1 || snapshot.Unmatched["delete"] != 1:
+		t.Errorf("Unexpected Rejected Or Unmatched: %v %v", snapshot.Rejected, snapshot.Unmatched)
+	case len(snapshot.Requests) != 1 || *snapshot.Requests["ping"] != RequestStats{Success: 1, Failure: 1}:
+		t.Errorf("Unexpected Request Stats: %v", snapshot.Requests)
+	}
+}
+
+// TestStats2 ensures retain only removes the endpoints rejected, and reset removes every count
+func TestStats2(t *testing.T) {
+	stats := NewStats()
+	stats.ObserveRequest("/a", "GET", 200, time.Millisecond)
+	stats.ObserveRequest("/b#Op", "POST", 200, time.Millisecond)
+	stats.ObserveUnmatched("GET")
+
+	stats.Retain(func(url string) bool { return url != "/a" })
+	if snapshot := stats.Snapshot(); len(snapshot.Endpoints) != 1 || snapshot.Endpoints["/b#Op"] == nil || snapshot.Unmatched["get"] != 1 {
+		t.Errorf("Unexpected Stats After Retain: %+v", snapshot)
+	}
+
+	stats.Reset()
+	if snapshot := stats.Snapshot(); len(snapshot.Endpoints) != 0 || len(snapshot.Unmatched) != 0 {
+		t.Errorf("Unexpected Stats After Reset: %+v", snapshot)
+	}
+}
+
+// TestStatsHandler1 ensures concurrent requests are all counted, and the stats are reset by a DELETE
+func TestStatsHandler1(t *testing.T) {
+	api := testHTTPAPI(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			api.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stats", nil))
+			api.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/__admin/v1/stats", nil))
+		}()
+	}
+	wg.Wait()
+
+	w := httptest.NewRecorder()
+	api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/__admin/v1/stats", nil))
+	snapshot := new(StatsSnapshot)
+	if err := json.Unmarshal(w.Body.Bytes(), snapshot); err != nil {
+		t.Fatalf("Unable To Decode Stats: %s", err.Error())
+	}
+	if count := snapshot.Endpoints["/stats"]["get"].StatusCodes[http.StatusTeapot]; count != 50 {
+		t.Errorf("Unexpected Count: %d", count)
+	}
+
+	w = httptest.NewRecorder()
+	api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/__admin/v1/stats", nil))
+	if w.Code != http.StatusNoContent || len(api.stats.Snapshot().Endpoints) != 0 {
+		t.Errorf("Stats Not Reset: %d", w.Code)
+	}
+}
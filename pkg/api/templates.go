@@ -0,0 +1,43 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"text/template"
+)
+
+// templateData is the incoming request data available to rawBody and bodyFile templates
+type templateData struct {
+	Method    string
+	Path      string
+	Query     url.Values
+	Headers   http.Header
+	Operation string // SOAP operation, empty for other endpoints
+	doc       *XMLNode
+}
+
+// renderTemplate renders the given text/template against the incoming request data
+func renderTemplate(content string, data *templateData) ([]byte, error) {
+	tmpl, err := template.New("body").Funcs(template.FuncMap{
+		// xpath returns the first value selected from an XML request body, or an empty string
+		"xpath": func(expr string) string {
+			if data.doc != nil {
+				if values, err := data.doc.XPath(expr); err == nil && len(values) > 0 {
+					return values[0]
+				}
+			}
+			return ""
+		},
+	}).Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Template: %s", err.Error())
+	}
+
+	buf := new(bytes.Buffer)
+	if err = tmpl.Execute(buf, data); err != nil {
+		return nil, fmt.Errorf("Unable To Render Template: %s", err.Error())
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+// nearMissLimit is the number of near-miss requests returned when a verification fails
+const nearMissLimit = 5
+
+/*Verification describes the requests expected to have been received and how many times. Requests are matched by method,
+path or endpoint and the same 'recieves' checks as an endpoint, at most one count constraint may be set and it defaults to
+at least once */
+type Verification struct {
+	Method   string           `yaml:"method,omitempty" json:"method,omitempty"`
+	Path     string           `yaml:"path,omitempty" json:"path,omitempty"` // request path, or an endpoint URL with ':name' segments
+	Endpoint string           `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	Since    string           `yaml:"since,omitempty" json:"since,omitempty"` // RFC 3339 time, earlier requests are ignored
+	Recieves *config.Recieves `yaml:"recieves,omitempty" json:"recieves,omitempty"`
+	Exactly  *int             `yaml:"exactly,omitempty" json:"exactly,omitempty"`
+	AtLeast  *int             `yaml:"atLeast,omitempty" json:"atLeast,omitempty"`
+	AtMost   *int             `yaml:"atMost,omitempty" json:"atMost,omitempty"`
+	Never    bool             `yaml:"never,omitempty" json:"never,omitempty"`
+}
+
+// VerifyResult is the outcome of a verification, with the requests closest to matching when it fails
+type VerifyResult struct {
+	Pass       bool            `json:"pass"`
+	Expected   string          `json:"expected"`
+	Count      int             `json:"count"`
+	Matched    []*JournalEntry `json:"matched"`
+	NearMisses []*NearMiss     `json:"nearMisses"`
+}
+
+// NearMiss is a request which did not match a verification, along with each reason it did not
+type NearMiss struct {
+	Request    *JournalEntry `json:"request"`
+	Mismatches []string      `json:"mismatches"`
+}
+
+// constraint returns the count constraint of the verification, as a check and a description
+func (v *Verification) constraint() (func(count int) bool, string, error) {
+	set := 0
+	for _, count := range []*int{v.Exactly, v.AtLeast, v.AtMost} {
+		if count != nil {
+			set++
+			if *count < 0 {
+				return nil, "", fmt.Errorf("Count Constraint %d Is Negative", *count)
+			}
+		}
+	}
+	if v.Never {
+		set++
+	}
+
+	switch {
+	case set > 1:
+		return nil, "", fmt.Errorf("Only One Of exactly, atLeast, atMost Or never May Be Set")
+	case v.Exactly != nil:
+		return func(count int) bool { return count == *v.Exactly }, fmt.Sprintf("exactly %d", *v.Exactly), nil
+	case v.AtMost != nil:
+		return func(count int) bool { return count <= *v.AtMost }, fmt.Sprintf("at most %d", *v.AtMost), nil
+	case v.Never:
+		return func(count int) bool { return count == 0 }, "never", nil
+	case v.AtLeast != nil:
+		return func(count int) bool { return count >= *v.AtLeast }, fmt.Sprintf("at least %d", *v.AtLeast), nil
+	default:
+		return func(count int) bool { return count >= 1 }, "at least 1", nil
+	}
+}
+
+// mismatches returns each reason the given request does not match the verification, none if it matches
+func (v *Verification) mismatches(entry *JournalEntry, since time.Time) []string {
+	result := make([]string, 0)
+	if len(v.Method) > 0 && !strings.EqualFold(v.Method, entry.Method) {
+		result = append(result, fmt.Sprintf("Method %s Is Not %s", entry.Method, strings.ToUpper(v.Method)))
+	}
+	if len(v.Path) > 0 && v.Path != entry.Path && !matchesPath(v.Path, entry.Path) {
+		result = append(result, fmt.Sprintf("Path %s Does Not Match %s", entry.Path, v.Path))
+	}
+	if len(v.Endpoint) > 0 && v.Endpoint != entry.Endpoint {
+		result = append(result, fmt.Sprintf("Endpoint %s Is Not %s", entry.Endpoint, v.Endpoint))
+	}
+	if !since.IsZero() && entry.ReceivedAt.Before(since) {
+		result = append(result, fmt.Sprintf("Received Before %s", v.Since))
+	}
+
+	if in := v.Recieves; in != nil {
+		if len(in.Headers) > 0 {
+			if err := evaluateHeaders(in, http.Header(entry.Headers)); err != nil {
+				result = append(result, err.Error())
+			}
+		}
+		if len(in.Body) > 0 {
+			if err := evaluateBody(in, []byte(entry.Body)); err != nil {
+				result = append(result, err.Error())
+			}
+		}
+		if len(in.XPath) > 0 {
+			if doc, err := ParseXML([]byte(entry.Body)); err != nil {
+				result = append(result, "Error Decoding Incoming Body")
+			} else if err := evaluateXPath(in, doc); err != nil {
+				result = append(result, err.Error())
+			}
+		}
+	}
+	return result
+}
+
+// near checks if a request shares the method or path of the verification, so is close enough to be worth reporting
+func (v *Verification) near(entry *JournalEntry) bool {
+	if len(v.Method) == 0 && len(v.Path) == 0 {
+		return true
+	}
+	return (len(v.Method) > 0 && strings.EqualFold(v.Method, entry.Method)) ||
+		(len(v.Path) > 0 && (v.Path == entry.Path || matchesPath(v.Path, entry.Path)))
+}
+
+// Verify checks the requests in the journal against the verification
+func (j *Journal) Verify(v *Verification) (*VerifyResult, error) {
+	check, expected, err := v.constraint()
+	if err != nil {
+		return nil, err
+	}
+	var since time.Time
+	if len(v.Since) > 0 {
+		if since, err = time.Parse(time.RFC3339Nano, v.Since); err != nil {
+			return nil, fmt.Errorf("since Is Not An RFC 3339 Time")
+		}
+	}
+
+	result := &VerifyResult{Expected: expected, Matched: make([]*JournalEntry, 0), NearMisses: make([]*NearMiss, 0)}
+	for _, entry := range j.Query(nil) {
+		mismatches := v.mismatches(entry, since)
+		switch {
+		case len(mismatches) == 0:
+			result.Matched = append(result.Matched, entry)
+		case v.near(entry):
+			result.NearMisses = append(result.NearMisses, &NearMiss{Request: entry, Mismatches: mismatches})
+		}
+	}
+	result.Count = len(result.Matched)
+	result.Pass = check(result.Count)
+
+	// the closest near misses first, the most recent first between those as close
+	sort.Slice(result.NearMisses, func(i, k int) bool {
+		a, b := result.NearMisses[i], result.NearMisses[k]
+		if len(a.Mismatches) != len(b.Mismatches) {
+			return len(a.Mismatches) < len(b.Mismatches)
+		}
+		return a.Request.ID > b.Request.ID
+	})
+	if result.Pass {
+		result.NearMisses = result.NearMisses[:0]
+	} else if len(result.NearMisses) > nearMissLimit {
+		result.NearMisses = result.NearMisses[:nearMissLimit]
+	}
+	return result, nil
+}
+
+// verifyHandler checks the journal against the verification in the request body, returning a 417 when it fails
+func (api *HTTPAPI) verifyHandler(w http.ResponseWriter, r *http.Request) int {
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return api.adminError(w, &HTTPError{"Error Reading Incoming Body", http.StatusInternalServerError})
+	}
+
+	verification := new(Verification)
+	if err := yaml.UnmarshalStrict(content, verification); err != nil {
+		return api.adminError(w, &HTTPError{fmt.Sprintf("Invalid Verification: %s", err.Error()), http.StatusBadRequest})
+	}
+
+	result, err := api.journal.Verify(verification)
+	if err != nil {
+		return api.adminError(w, &HTTPError{fmt.Sprintf("Invalid Verification: %s", err.Error()), http.StatusBadRequest})
+	}
+	if !result.Pass {
+		return api.writeJSON(w, http.StatusExpectationFailed, result)
+	}
+	return api.writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// testVerifyJournal returns a journal holding two POST requests and one GET request to the same path
+func testVerifyJournal() *Journal {
+	journal := NewJournal(10)
+	journal.Record(&JournalEntry{Method: "POST", Path: "/api/v1/job", Body: `{"uid": "abc"}`})
+	journal.Record(&JournalEntry{Method: "POST", Path: "/api/v1/job", Body: `{"name": "abc"}`})
+	journal.Record(&JournalEntry{Method: "GET", Path: "/api/v1/job/24"})
+	return journal
+}
+
+// TestVerify1 ensures each count constraint is checked against the requests matched
+func TestVerify1(t *testing.T) {
+	journal := testVerifyJournal()
+	zero, one, two := 0, 1, 2
+
+	for _, entry := range []struct {
+		verification *Verification
+		pass         bool
+	}{
+		{&Verification{Method: "post", Path: "/api/v1/job"}, true},
+		{&Verification{Method: "post", Exactly: &one}, false},
+		{&Verification{Method: "post", Exactly: &two}, true},
+		{&Verification{Path: "/api/v1/job/:id", AtLeast: &two}, false},
+		{&Verification{Path: "/api/v1/job/:id", AtMost: &one}, true},
+		{&Verification{Method: "delete", Never: true}, true},
+		{&Verification{Method: "delete", Exactly: &zero}, true},
+		{&Verification{Method: "get", Never: true}, false},
+	} {
+		result, err := journal.Verify(entry.verification)
+		if err != nil {
+			t.Fatalf("Unable To Verify: %s", err.Error())
+		}
+		if result.Pass != entry.pass {
+			t.Errorf("Verification %+v Did Not Return %t: %+v", entry.verification, entry.pass, result)
+		}
+	}
+
+	if _, err := journal.Verify(&Verification{Exactly: &one, Never: true}); err == nil {
+		t.Errorf("Verification With Two Count Constraints Returned No Error")
+	}
+}
+
+/*TestVerify2 ensures requests are matched by the 'recieves' checks of an endpoint, and failures return near misses sharing
+the method or path, the most recent first */
+func TestVerify2(t *testing.T) {
+	one := 1
+	result, err := testVerifyJournal().Verify(&Verification{
+		Method:   "post",
+		Path:     "/api/v1/job",
+		Recieves: &config.Recieves{Body: map[string]string{"uid": "integer"}},
+		Exactly:  &one,
+	})
+	if err != nil {
+		t.Fatalf("Unable To Verify: %s", err.Error())
+	}
+
+	if result.Pass || result.Count != 0 || len(result.NearMisses) != 2 {
+		t.Fatalf("Unexpected Result: %+v", result)
+	}
+	if miss := result.NearMisses[0]; miss.Request.Body != `{"name": "abc"}` || len(miss.Mismatches) != 1 {
+		t.Errorf("Unexpected Near Miss: %+v", miss)
+	}
+
+	result, _ = testVerifyJournal().Verify(&Verification{
+		Method:   "get",
+		Path:     "/api/v1/job",
+		Recieves: &config.Recieves{Body: map[string]string{"uid": "string"}},
+	})
+	if len(result.NearMisses) != 3 || result.NearMisses[0].Request.Body != `{"uid": "abc"}` || result.NearMisses[1].Request.Method != "GET" {
+		t.Errorf("Near Misses Not Ordered Closest Then Most Recent First: %+v", result.NearMisses)
+	}
+}
+
+// TestVerifyHandler1 ensures a failed verification is returned as a 417
+func TestVerifyHandler1(t *testing.T) {
+	api := testHTTPAPI(nil)
+	api.mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stats", nil))
+
+	for body, statusCode := range map[string]int{
+		`{"method": "get", "path": "/stats", "exactly": 1}`: http.StatusOK,
+		"method: get\npath: /stats\nnever: true\n":          http.StatusExpectationFailed,
+		`{"method": "get", "unknown": 1}`:                   http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		api.mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/__admin/v1/verify", strings.NewReader(body)))
+		if w.Code != statusCode {
+			t.Errorf("Unexpected Status Code For %s: %d", body, w.Code)
+		}
+	}
+}
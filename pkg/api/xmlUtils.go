@@ -0,0 +1,187 @@
+package api
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// XMLNode is a minimal element tree parsed from an XML document, names are held without their namespace prefix
+type XMLNode struct {
+	Name     string
+	Attrs    map[string]string
+	Text     string
+	Children []*XMLNode
+}
+
+// ParseXML parses the given raw XML document into an XMLNode tree, returning the root element
+func ParseXML(data []byte) (*XMLNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var root *XMLNode
+	stack := make([]*XMLNode, 0)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid XML: %s", err.Error())
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &XMLNode{Name: t.Name.Local, Attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				node.Attrs[attr.Name.Local] = attr.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text = strings.TrimSpace(stack[len(stack)-1].Text)
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += string(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("Invalid XML: No Root Element")
+	}
+	return root, nil
+}
+
+// Child returns the first direct child element with the given name, or nil if there is none
+func (n *XMLNode) Child(name string) *XMLNode {
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+/*
+XPath evaluates a subset of XPath against the tree, returning the string values of all matches
+supported: absolute '/a/b', descendant '//b', wildcard '*', positional '[n]' predicates, and a trailing '@attr' or 'text()' step.
+namespace prefixes in the expression are ignored, matching the prefix-less names held in the tree
+*/
+func (n *XMLNode) XPath(expr string) ([]string, error) {
+	if len(expr) == 0 {
+		return nil, fmt.Errorf("Empty XPath Expression")
+	}
+
+	// the root element is treated as the child of a virtual document node
+	current := []*XMLNode{{Children: []*XMLNode{n}}}
+	rest := expr
+	if !strings.HasPrefix(rest, "/") {
+		rest = "//" + rest
+	}
+
+	for len(rest) > 0 {
+		descendant := strings.HasPrefix(rest, "//")
+		rest = strings.TrimLeft(rest, "/")
+
+		step := rest
+		if i := strings.Index(rest, "/"); i >= 0 {
+			step, rest = rest[:i], rest[i:]
+		} else {
+			rest = ""
+		}
+
+		if step == "text()" || strings.HasPrefix(step, "@") {
+			if len(rest) > 0 {
+				return nil, fmt.Errorf("XPath %s: %s Must Be The Last Step", expr, step)
+			}
+			values := make([]string, 0, len(current))
+			for _, node := range current {
+				if step == "text()" {
+					values = append(values, node.Text)
+				} else if value, found := node.Attrs[stripXMLPrefix(step[1:])]; found {
+					values = append(values, value)
+				}
+			}
+			return values, nil
+		}
+
+		name, position, err := parseXPathStep(step)
+		if err != nil {
+			return nil, fmt.Errorf("XPath %s: %s", expr, err.Error())
+		}
+
+		next := make([]*XMLNode, 0)
+		for _, node := range current {
+			candidates := node.Children
+			if descendant {
+				candidates = node.descendants()
+			}
+			matched := 0
+			for _, candidate := range candidates {
+				if name == "*" || candidate.Name == name {
+					matched++
+					if position == 0 || matched == position {
+						next = append(next, candidate)
+					}
+				}
+			}
+		}
+		current = next
+	}
+
+	values := make([]string, 0, len(current))
+	for _, node := range current {
+		values = append(values, node.Text)
+	}
+	return values, nil
+}
+
+// descendants returns every element below this one in document order
+func (n *XMLNode) descendants() []*XMLNode {
+	result := make([]*XMLNode, 0)
+	for _, child := range n.Children {
+		result = append(result, child)
+		result = append(result, child.descendants()...)
+	}
+	return result
+}
+
+// parseXPathStep splits an XPath step such as 'ns:Item[2]' into its prefix-less name and position, zero meaning any position
+func parseXPathStep(step string) (string, int, error) {
+	if len(step) == 0 {
+		return "", 0, fmt.Errorf("Empty Step")
+	}
+
+	position := 0
+	if i := strings.Index(step, "["); i >= 0 {
+		if !strings.HasSuffix(step, "]") {
+			return "", 0, fmt.Errorf("Unterminated Predicate In Step %s", step)
+		}
+		value, err := strconv.Atoi(step[i+1 : len(step)-1])
+		if err != nil || value < 1 {
+			return "", 0, fmt.Errorf("Unsupported Predicate In Step %s", step)
+		}
+		step, position = step[:i], value
+	}
+
+	return stripXMLPrefix(step), position, nil
+}
+
+// stripXMLPrefix removes any namespace prefix from the given name
+func stripXMLPrefix(name string) string {
+	if i := strings.Index(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
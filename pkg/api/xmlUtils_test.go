@@ -0,0 +1,68 @@
+package api
+
+import "testing"
+
+// testSOAPEnvelope is a namespaced SOAP request used across the XML tests
+const testSOAPEnvelope = `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:p="urn:payments">
+	<soap:Body>
+		<p:Authorise currency="GBP">
+			<p:Amount>10.50</p:Amount>
+			<p:Item>first</p:Item>
+			<p:Item>second</p:Item>
+		</p:Authorise>
+	</soap:Body>
+</soap:Envelope>`
+
+// TestXPath1 ensures the supported XPath forms select the expected values
+func TestXPath1(t *testing.T) {
+	doc, err := ParseXML([]byte(testSOAPEnvelope))
+	if err != nil {
+		t.Fatalf("Error Encountered Parsing XML: %s", err.Error())
+	}
+
+	for expr, expected := range map[string]string{
+		"/soap:Envelope/soap:Body/p:Authorise/p:Amount": "10.50",
+		"/Envelope/Body/Authorise/Amount/text()":        "10.50",
+		"//Amount":                                      "10.50",
+		"Amount":                                        "10.50",
+		"//Authorise/Item[2]":                           "second",
+		"/Envelope/*/Authorise/@currency":               "GBP",
+	} {
+		values, err := doc.XPath(expr)
+		if err != nil {
+			t.Errorf("Error Encountered Evaluating %s: %s", expr, err.Error())
+			continue
+		}
+		if len(values) == 0 || values[0] != expected {
+			t.Errorf("XPath %s Did Not Return %s: %v", expr, expected, values)
+		}
+	}
+}
+
+// TestXPath2 ensures missing elements return no values and malformed expressions return errors
+func TestXPath2(t *testing.T) {
+	doc, err := ParseXML([]byte(testSOAPEnvelope))
+	if err != nil {
+		t.Fatalf("Error Encountered Parsing XML: %s", err.Error())
+	}
+
+	if values, err := doc.XPath("/Envelope/Header"); err != nil || len(values) != 0 {
+		t.Errorf("Missing Element Returned Values: %v", values)
+	}
+
+	for _, expr := range []string{"", "//Item[0]", "//Item[1", "/Envelope/@id/Body"} {
+		if _, err := doc.XPath(expr); err == nil {
+			t.Errorf("Invalid XPath '%s' Returned No Error", expr)
+		}
+	}
+}
+
+// TestParseXML1 ensures invalid documents are returned as errors
+func TestParseXML1(t *testing.T) {
+	for _, data := range []string{"", "not xml", "<open>"} {
+		if _, err := ParseXML([]byte(data)); err == nil {
+			t.Errorf("Invalid XML '%s' Returned No Error", data)
+		}
+	}
+}
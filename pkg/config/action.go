@@ -0,0 +1,84 @@
+package config
+
+// Action represents a single follow-on action, exactly one of its fields is set
+type Action struct {
+	Delay   *DelayAction   `yaml:"delay,omitempty"`
+	Request *RequestAction `yaml:"request,omitempty"`
+}
+
+// DelayAction pauses before running the remaining actions
+type DelayAction struct {
+	Seconds int `yaml:"seconds"`
+}
+
+// RequestAction sends a request defined in the 'requests' section to a service
+type RequestAction struct {
+	Target string `yaml:"target"`
+	ID     string `yaml:"id"`
+}
+
+// Name returns the name of the action type set
+func (a *Action) Name() string {
+	switch {
+	case a.Delay != nil:
+		return "delay"
+	case a.Request != nil:
+		return "request"
+	default:
+		return ""
+	}
+}
+
+// count returns the number of action types set, a valid action has exactly one
+func (a *Action) count() int {
+	count := 0
+	if a.Delay != nil {
+		count++
+	}
+	if a.Request != nil {
+		count++
+	}
+	return count
+}
+
+/*ValidateActions validates a list of actions as Validate would were they defined in the given config, which must already be
+validated. Every problem found is returned as ValidationErrors */
+func ValidateActions(cfg *Config, actions []*Action) error {
+	errs := new(errorList)
+
+	if cfg.Version >= 2.0 {
+		validateV2Actions("", actions, errs)
+	}
+	errs.merge("", validateV1Actions(actions, cfg.serviceNames(), cfg.Requests))
+
+	return errs.result()
+}
+
+// serviceNames returns the name of every service of a validated config
+func (c *Config) serviceNames() map[string]bool {
+	names := make(map[string]bool, len(c.Services))
+	for name := range c.Services {
+		names[name] = true
+	}
+	return names
+}
+
+// UnmarshalYAML accepts the v1 form of a delay, a number of seconds, as well as the v2 mapping
+func (d *DelayAction) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	if _, isMap := raw.(map[interface{}]interface{}); !isMap {
+		return unmarshal(&d.Seconds)
+	}
+
+	type plain DelayAction
+	return unmarshal((*plain)(d))
+}
+
+// schemaAlternatives describes the v1 form of a delay in the JSON Schema
+func (d *DelayAction) schemaAlternatives() []map[string]interface{} {
+	return []map[string]interface{}{{"type": "integer"}, envRefSchema}
+}
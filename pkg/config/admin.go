@@ -0,0 +1,44 @@
+package config
+
+import "strings"
+
+// DefaultAdminPrefix is the path the admin API is served under when no prefix is configured
+const DefaultAdminPrefix = "/__admin"
+
+// DefaultJournalSize is the number of requests kept in the journal when no size is configured
+const DefaultJournalSize = 1000
+
+// Admin represents the settings of the built-in admin API
+type Admin struct {
+	Prefix  string `yaml:"prefix,omitempty"`  // path the admin API is served under, defaults to /__admin
+	Port    int    `yaml:"port,omitempty"`    // serves the admin API on its own port rather than alongside the endpoints
+	Token   string `yaml:"token,omitempty"`   // bearer token required by every admin request
+	Journal int    `yaml:"journal,omitempty"` // number of requests kept in the journal, defaults to 1000
+}
+
+// PathPrefix returns the path the admin API is served under, which is the default for a nil Admin
+func (a *Admin) PathPrefix() string {
+	if a == nil || len(a.Prefix) == 0 {
+		return DefaultAdminPrefix
+	}
+	return strings.TrimSuffix(a.Prefix, "/")
+}
+
+// JournalSize returns the number of requests kept in the journal, which is the default for a nil Admin
+func (a *Admin) JournalSize() int {
+	if a == nil || a.Journal == 0 {
+		return DefaultJournalSize
+	}
+	return a.Journal
+}
+
+// Matches checks if the given request path is served by the admin API
+func (a *Admin) Matches(path string) bool {
+	prefix := a.PathPrefix()
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// servedByAdmin checks if the admin API is matched before the given endpoint URL, which it is when they share a port
+func (c *Config) servedByAdmin(url string) bool {
+	return (c.Admin == nil || c.Admin.Port == 0) && c.Admin.Matches(url)
+}
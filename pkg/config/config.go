@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
 /* save os/io/json methods to variables to allow mocking, normally these would be done through
@@ -13,36 +15,215 @@ a mock interface but its only for the LoadFromFile method so not worth the effor
 var osStat = os.Stat
 var ioReadFile = ioutil.ReadFile
 var yamlUnmarshal = yaml.Unmarshal
+var yamlUnmarshalStrict = yaml.UnmarshalStrict
 
 // Config holds all the data required to operate the application
 type Config struct {
-	Version        float32                         `yaml:"version"`
-	Services       map[string]*Service             `yaml:"services"`
-	StartupActions []map[string]interface{}        `yaml:"startupActions"`
-	Requests       map[string]*Request             `yaml:"requests"`
-	Endpoints      map[string]map[string]*Endpoint `yaml:"endpoints"` // url -> method : endpoint
+	Version        float32                         `yaml:"version,omitempty"`
+	Include        []string                        `yaml:"include,omitempty"` // globs of further config files, relative to the declaring file
+	Services       map[string]*Service             `yaml:"services,omitempty"`
+	StartupActions []*Action                       `yaml:"startupActions,omitempty"`
+	Requests       map[string]*Request             `yaml:"requests,omitempty"`
+	Endpoints      map[string]map[string]*Endpoint `yaml:"endpoints,omitempty"` // url -> method : endpoint
+	Listeners      map[string]*Listener            `yaml:"listeners,omitempty"`
+	Proxy          *Proxy                          `yaml:"proxy,omitempty"`
+	Admin          *Admin                          `yaml:"admin,omitempty"`
+	Startup        *Startup                        `yaml:"startup,omitempty"`
+
+	file      string              // path the config was loaded from
+	files     []string            // every file and directory the config was loaded from, watched for changes
+	positions map[string]position // config path -> position in the YAML, used to locate validation errors
 }
 
-// LoadFromFile creates a new Config object from the given filepath
+/*LoadFromFile creates a new Config object from the given filepath, along with every file it includes, expanding '${...}'
+references. When the path is a directory every '*.yml' and '*.yaml' file within it is loaded and merged */
 func LoadFromFile(path string) (*Config, error) {
-	if len(path) == 0 {
-		return nil, fmt.Errorf("Invalid Path %s", path)
+	return load([]string{path}, true)
+}
+
+// LoadFromFiles creates a new Config object as LoadFromFile from the first path, deep-merging every following path as an overlay
+func LoadFromFiles(paths []string) (*Config, error) {
+	return load(paths, true)
+}
+
+// LoadRawFromFile creates a new Config object as LoadFromFile, keeping '${...}' references for configs which are written back out
+func LoadRawFromFile(path string) (*Config, error) {
+	return load([]string{path}, false)
+}
+
+// LoadRawFromFiles creates a new Config object as LoadFromFiles, keeping '${...}' references for configs which are written back out
+func LoadRawFromFiles(paths []string) (*Config, error) {
+	return load(paths, false)
+}
+
+// load creates a new Config object from a base path and overlays, optionally expanding '${...}' references
+func load(paths []string, interpolate bool) (*Config, error) {
+	if len(paths) == 0 || len(paths[0]) == 0 {
+		return nil, fmt.Errorf("Invalid Path %s", strings.Join(paths, ", "))
 	}
+	path := paths[0]
 
-	_, err := osStat(path)
+	info, err := osStat(path)
 	if err != nil {
 		return nil, fmt.Errorf("File %s Not Found", path)
 	}
 
-	content, err := ioReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("Unable To Read File %s: %s", path, err.Error())
+	files := []string{path}
+	if info.IsDir() {
+		if files, err = configFiles(path); err != nil {
+			return nil, err
+		}
 	}
 
-	cfg := new(Config)
-	if err = yamlUnmarshal(content, &cfg); err != nil {
+	l := newLoader(interpolate)
+	for _, file := range files {
+		content, err := ioReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("Unable To Read File %s: %s", file, err.Error())
+		}
+		if err := l.loadFile(file, content); err != nil {
+			return nil, err
+		}
+	}
+	if info.IsDir() {
+		l.watched = append(l.watched, path)
+	}
+
+	// overlays are only merged into a base which loaded without errors
+	if len(l.errs) > 0 {
+		return nil, l.errs
+	}
+
+	l.cfg.Include = nil
+	l.cfg.file = path
+	if err := l.applyOverlays(paths[1:]); err != nil {
+		return nil, err
+	}
+	if len(l.errs) > 0 {
+		return nil, l.errs
+	}
+
+	cfg := l.cfg
+	cfg.Include = nil
+	cfg.file = path
+	cfg.files = l.watched
+	return cfg, nil
+}
+
+// Files returns every file the config was loaded from, along with the directories searched for config files
+func (c *Config) Files() []string {
+	return c.files
+}
+
+// Parse creates a new Config object from YAML, version 2 configs are decoded strictly rejecting unknown fields
+func Parse(content []byte) (*Config, error) {
+	var probe struct {
+		Version float32 `yaml:"version"`
+	}
+	if err := yamlUnmarshal(content, &probe); err != nil {
 		return nil, fmt.Errorf("Unable To Unmarshal Cfg: %s", err.Error())
 	}
 
+	unmarshal := yamlUnmarshal
+	if probe.Version >= 2.0 {
+		unmarshal = yamlUnmarshalStrict
+	}
+
+	positions := nodePositions(content)
+
+	cfg := new(Config)
+	if err := unmarshal(content, &cfg); err != nil {
+		err = decodeErrors(err)
+		if errs, valid := err.(ValidationErrors); valid {
+			errs.locate("", positions)
+		}
+		return nil, err
+	}
+
+	cfg.positions = positions
 	return cfg, nil
 }
+
+// ToJSON converts a config, or any value within one, into values which can be marshalled to JSON, keyed as they are in YAML
+func ToJSON(value interface{}) interface{} {
+	data, _ := yaml.Marshal(value)
+	var raw interface{}
+	yaml.Unmarshal(data, &raw)
+	return stringKeys(raw)
+}
+
+// stringKeys converts the maps decoded from YAML into maps with string keys
+func stringKeys(input interface{}) interface{} {
+	switch value := input.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			result[fmt.Sprint(k)] = stringKeys(v)
+		}
+		return result
+	case []interface{}:
+		for i, v := range value {
+			value[i] = stringKeys(v)
+		}
+	}
+	return input
+}
+
+// WithEndpoints returns a copy of the config serving the given endpoints in place of its own, the config itself is unchanged
+func (c *Config) WithEndpoints(endpoints map[string]map[string]*Endpoint) *Config {
+	copied := *c
+	copied.Endpoints = endpoints
+	return &copied
+}
+
+// Migrate converts a loaded config to the latest version in place
+func Migrate(cfg *Config) error {
+	switch cfg.Version {
+	case 1.0:
+		// v2 shares the v1 schema, but v1 allowed a single entry to hold several actions
+		for location, actions := range configActions(cfg) {
+			split := make([]*Action, 0, len(*actions))
+			for i, action := range *actions {
+				if action == nil || action.count() == 0 {
+					return fmt.Errorf("Action %s Not Supported", joinPath(location, i))
+				}
+				if action.Delay != nil {
+					split = append(split, &Action{Delay: action.Delay})
+				}
+				if action.Request != nil {
+					split = append(split, &Action{Request: action.Request})
+				}
+			}
+			*actions = split
+		}
+		cfg.Version = 2.0
+		return nil
+	case 2.0:
+		return fmt.Errorf("Config Is Already Version 2")
+	default:
+		return fmt.Errorf("Unsupported Version: %f", cfg.Version)
+	}
+}
+
+/*HasComments returns whether the given YAML document holds any comments. A config written back out is generated from the
+decoded config, so comments and the order of keys are lost and a commented file should not be overwritten */
+func HasComments(content []byte) bool {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &root); err != nil {
+		return false
+	}
+
+	var walk func(node *yamlv3.Node) bool
+	walk = func(node *yamlv3.Node) bool {
+		if len(node.HeadComment) > 0 || len(node.LineComment) > 0 || len(node.FootComment) > 0 {
+			return true
+		}
+		for _, child := range node.Content {
+			if walk(child) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(&root)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"testing"
+
+	"gopkg.in/yaml.v2"
 )
 
 // TestLoadFromFile1 tests loading an example file and parsing into a config, uses fully mocked data and just checks the code runs
@@ -122,3 +124,58 @@ func mockUnmarshalInvalid(in []byte, out interface{}) error {
 	out = nil
 	return fmt.Errorf("Test Error")
 }
+
+// TestParse1 ensures unknown fields are accepted in a v1 config but rejected in a v2 config
+func TestParse1(t *testing.T) {
+	yamlUnmarshal = yaml.Unmarshal
+	yamlUnmarshalStrict = yaml.UnmarshalStrict
+
+	if _, err := Parse([]byte("version: 1.0\nanchors: []\nstartupActions:\n  - delay: 5\n")); err != nil {
+		t.Errorf("Error Encountered Parsing v1 Config: %s", err.Error())
+	}
+
+	if _, err := Parse([]byte("version: 2.0\nstartupActions:\n  - delay: {seconds: 5, unit: ms}\n")); err == nil {
+		t.Errorf("Unknown Field In v2 Config Returned No Error")
+	}
+}
+
+// TestMigrate1 ensures a v1 config is upgraded to v2, splitting entries holding several actions
+func TestMigrate1(t *testing.T) {
+	cfg := &Config{
+		Version: 1.0,
+		StartupActions: []*Action{
+			{Delay: &DelayAction{Seconds: 1}, Request: &RequestAction{Target: "testService", ID: "testRequest"}},
+		},
+	}
+
+	if err := Migrate(cfg); err != nil {
+		t.Fatalf("Error Encountered Migrating: %s", err.Error())
+	}
+
+	if cfg.Version != 2.0 || len(cfg.StartupActions) != 2 {
+		t.Errorf("Config Not Migrated: %+v", cfg)
+	}
+	if cfg.StartupActions[0].Delay == nil || cfg.StartupActions[1].Request == nil {
+		t.Errorf("Actions Not Split In Order")
+	}
+
+	if err := Migrate(cfg); err == nil {
+		t.Errorf("Migrating A v2 Config Returned No Error")
+	}
+}
+
+// TestHasComments1 ensures comments are found wherever they are written, and a '#' within a value is not a comment
+func TestHasComments1(t *testing.T) {
+	for content, expected := range map[string]bool{
+		"# header\nversion: 1.0\n":                       true,
+		"version: 1.0 # trailing\n":                      true,
+		"endpoints:\n  /a:\n    # nested\n    get: {}\n": true,
+		"version: 1.0\nendpoints: {}\n# footer\n":        true,
+		"version: 1.0\nname: 'a # b'\nurl: /a#b\n":       false,
+		"": false,
+	} {
+		if HasComments([]byte(content)) != expected {
+			t.Errorf("Comments In %q Not Found As Expected: %t", content, expected)
+		}
+	}
+}
@@ -1,28 +1,108 @@
 package config
 
+import (
+	"strconv"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
 // Endpoint represents a definition for an endpoint
 type Endpoint struct {
-	Params    *Parameters              `yaml:"params"`
-	Recieves  *Recieves                `yaml:"recieves"`
-	Response  int                      `yaml:"response"`
-	Responses map[int]*Response        `yaml:"responses"`
-	Actions   []map[string]interface{} `yaml:"actions"`
+	Params    *Parameters          `yaml:"params,omitempty"`
+	Recieves  *Recieves            `yaml:"recieves,omitempty"`
+	Response  int                  `yaml:"response,omitempty"`
+	Responses map[int]*Response    `yaml:"responses,omitempty"`
+	Actions   []*Action            `yaml:"actions,omitempty"`
+	SOAP      map[string]*Endpoint `yaml:"soap,omitempty"` // SOAPAction or first body element -> operation
 }
 
 // Parameters represents the parameters in an HTTP endpoint
 type Parameters struct {
-	Query map[string]*ParamEntry `yaml:"query"`
-	Path  map[string]*ParamEntry `yaml:"path"`
+	Query map[string]*ParamEntry `yaml:"query,omitempty"`
+	Path  map[string]*ParamEntry `yaml:"path,omitempty"`
 }
 
 // ParamEntry represents the parameters for a single parameter
 type ParamEntry struct {
-	Type     string `yaml:"type"`
-	Required bool   `yaml:"required"`
+	Type     string `yaml:"type,omitempty"`
+	Required bool   `yaml:"required,omitempty"`
 }
 
 // Recieves represents the 'recieves' field of an endpoint
 type Recieves struct {
-	Headers map[string]string `yaml:"headers"`
-	Body    map[string]string `yaml:"body"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Body    map[string]string `yaml:"body,omitempty"`
+	XPath   map[string]string `yaml:"xpath,omitempty"` // xpath -> expected type, for XML bodies
+}
+
+/*ParseEndpoints decodes endpoint definitions from YAML or JSON, keyed by URL then method as in the 'endpoints' section of a
+config. Definitions are decoded strictly and '${...}' references are expanded, as they are when a config file is loaded */
+func ParseEndpoints(content []byte) (map[string]map[string]*Endpoint, error) {
+	content, err := interpolate(content)
+	if err != nil {
+		return nil, err
+	}
+	content = unquoteStatusCodes(content)
+
+	endpoints := make(map[string]map[string]*Endpoint)
+	if err := yamlUnmarshalStrict(content, &endpoints); err != nil {
+		err = decodeErrors(err)
+		if errs, valid := err.(ValidationErrors); valid {
+			errs.locate("", nodePositions(content))
+		}
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+/*ValidateEndpoint validates a single endpoint definition as Validate would were it defined in the given config, which must
+already be validated. Every problem found is returned as ValidationErrors */
+func ValidateEndpoint(cfg *Config, url, method string, entry *Endpoint) error {
+	errs := new(errorList)
+
+	if !strings.HasPrefix(url, "/") {
+		errs.add(joinPath("endpoints", url), "URL %s Must Begin With /", url)
+	}
+	if cfg.servedByAdmin(url) {
+		errs.add(joinPath("endpoints", url), "Endpoint Is Served By The Admin API At %s", cfg.Admin.PathPrefix())
+	}
+
+	single := &Config{Endpoints: map[string]map[string]*Endpoint{url: {method: entry}}}
+	if cfg.Version >= 2.0 {
+		validateV2ActionTypes(single, errs)
+	}
+
+	errs.merge(joinPath("endpoints", url, method), validateV1Endpoint(url, method, entry, cfg.serviceNames(), cfg.Requests))
+
+	return errs.result()
+}
+
+// unquoteStatusCodes converts the quoted status codes JSON keys responses by into the integers they are in YAML
+func unquoteStatusCodes(content []byte) []byte {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &root); err != nil || !strings.Contains(string(content), `"`) {
+		// the document is reported as invalid when it is decoded
+		return content
+	}
+
+	var walk func(node *yamlv3.Node)
+	walk = func(node *yamlv3.Node) {
+		for i, child := range node.Content {
+			if node.Kind == yamlv3.MappingNode && i%2 == 1 && node.Content[i-1].Value == "responses" && child.Kind == yamlv3.MappingNode {
+				for j := 0; j < len(child.Content); j += 2 {
+					if _, err := strconv.Atoi(child.Content[j].Value); err == nil {
+						child.Content[j].Style, child.Content[j].Tag = 0, "!!int"
+					}
+				}
+			}
+			walk(child)
+		}
+	}
+	walk(&root)
+
+	if result, err := yamlv3.Marshal(&root); err == nil {
+		return result
+	}
+	return content
 }
@@ -0,0 +1,50 @@
+package config
+
+import "testing"
+
+// TestParseEndpoints1 ensures endpoints are decoded from JSON with quoted status codes as well as from YAML
+func TestParseEndpoints1(t *testing.T) {
+	for _, content := range []string{
+		`{"/a": {"get": {"responses": {"201": {"weight": 100, "body": {"200": "kept"}}}}}}`,
+		"/a:\n  get:\n    responses:\n      201:\n        weight: 100\n        body:\n          '200': kept\n",
+	} {
+		endpoints, err := ParseEndpoints([]byte(content))
+		if err != nil {
+			t.Fatalf("Unable To Parse Endpoints: %s", err.Error())
+		}
+		resp := endpoints["/a"]["get"].Responses[201]
+		if resp == nil || resp.Weight != 100 || resp.Body["200"] != "kept" {
+			t.Errorf("Unexpected Endpoint Parsed From %s: %+v", content, endpoints["/a"]["get"])
+		}
+	}
+
+	if _, err := ParseEndpoints([]byte(`{"/a": {"get": {"respons": 200}}}`)); err == nil {
+		t.Errorf("Unknown Field Returned No Error")
+	}
+}
+
+// TestValidateEndpoint1 ensures endpoints are validated against the services and requests of the config
+func TestValidateEndpoint1(t *testing.T) {
+	cfg := &Config{
+		Version:  2.0,
+		Services: map[string]*Service{"api": {Hostname: "localhost", Port: 8080}},
+		Requests: map[string]*Request{"ping": {URL: "/ping", Method: "get"}},
+	}
+
+	valid := &Endpoint{Response: 200, Actions: []*Action{{Request: &RequestAction{Target: "api", ID: "ping"}}}}
+	if err := ValidateEndpoint(cfg, "/a", "get", valid); err != nil {
+		t.Errorf("Valid Endpoint Returned Error: %s", err.Error())
+	}
+
+	for url, entry := range map[string]*Endpoint{
+		"/a":              {Response: 200, Actions: []*Action{{Request: &RequestAction{Target: "missing", ID: "ping"}}}},
+		"/b":              {Response: 200, Actions: []*Action{{Delay: &DelayAction{Seconds: 1}, Request: &RequestAction{Target: "api", ID: "ping"}}}},
+		"/c":              {},
+		"d":               {Response: 200},
+		"/__admin/v1/abc": {Response: 200},
+	} {
+		if err := ValidateEndpoint(cfg, url, "get", entry); err == nil {
+			t.Errorf("Invalid Endpoint %s Returned No Error", url)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+/*interpolate expands the '${...}' references in every key and value of a YAML document, returning the document unchanged
+when it holds none. A service hostname of only '$VAR' is expanded as '${VAR}', the form used before references. Every
+reference which cannot be resolved is returned as ValidationErrors, replaced with an empty value */
+func interpolate(content []byte) ([]byte, error) {
+	if !strings.Contains(string(content), "$") {
+		return content, nil
+	}
+
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &root); err != nil {
+		// the document is reported as invalid when it is decoded
+		return content, nil
+	}
+
+	errs := new(errorList)
+	expanded := false
+	expand := func(node *yamlv3.Node, path string) {
+		if node.Kind != yamlv3.ScalarNode {
+			return
+		}
+
+		field := node.Value
+		if isLegacyEnvRef(path, field) {
+			field = "${" + field[1:] + "}"
+		}
+		if !strings.Contains(field, "${") {
+			return
+		}
+		expanded = true
+
+		value, err := expandEnvRefs(field)
+		if err != nil {
+			errs.add(path, "%s", err.Error())
+		}
+		node.Value = value
+
+		// unquoted values are resolved again, so a reference to a number can be used for a port
+		if node.Style&(yamlv3.SingleQuotedStyle|yamlv3.DoubleQuotedStyle|yamlv3.LiteralStyle|yamlv3.FoldedStyle|yamlv3.TaggedStyle) == 0 {
+			node.Tag = ""
+		}
+	}
+
+	var walk func(node *yamlv3.Node, path string)
+	walk = func(node *yamlv3.Node, path string) {
+		switch node.Kind {
+		case yamlv3.DocumentNode:
+			for _, child := range node.Content {
+				walk(child, path)
+			}
+		case yamlv3.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := joinPath(path, node.Content[i].Value)
+				expand(node.Content[i], key)
+				walk(node.Content[i+1], key)
+			}
+		case yamlv3.SequenceNode:
+			for i, child := range node.Content {
+				walk(child, joinPath(path, i))
+			}
+		case yamlv3.ScalarNode:
+			expand(node, path)
+		}
+	}
+	walk(&root, "")
+
+	if !expanded {
+		return content, nil
+	}
+	result, err := yamlv3.Marshal(&root)
+	if err != nil {
+		return content, nil
+	}
+	return result, errs.result()
+}
+
+// isLegacyEnvRef checks if a value is a service hostname of only '$VAR'
+func isLegacyEnvRef(path, field string) bool {
+	if len(field) < 2 || field[0] != '$' || field[1] == '{' || field[1] == '$' {
+		return false
+	}
+	service := parentPath(path)
+	return path == joinPath(service, "hostname") && parentPath(service) == "services"
+}
+
+/*expandEnvRefs replaces every '${VAR}' reference within the given string. A reference may give a default with '${VAR:-default}',
+or read a secret from a file with '${file:/path}', whilst '$${' is written as a literal '${'. Every reference which cannot be
+resolved is returned in a single error */
+func expandEnvRefs(field string) (string, error) {
+	var result strings.Builder
+	var failed []string
+
+	for {
+		start := strings.Index(field, "${")
+		if start < 0 {
+			break
+		}
+		if start > 0 && field[start-1] == '$' {
+			result.WriteString(field[:start-1])
+			result.WriteString("${")
+			field = field[start+2:]
+			continue
+		}
+		end := strings.Index(field[start:], "}")
+		if end < 0 {
+			break
+		}
+
+		value, err := resolveEnvRef(field[start+2 : start+end])
+		if err != nil {
+			failed = append(failed, err.Error())
+		}
+		result.WriteString(field[:start])
+		result.WriteString(value)
+		field = field[start+end+1:]
+	}
+
+	if len(failed) > 0 {
+		return "", fmt.Errorf("%s", strings.Join(failed, ", "))
+	}
+
+	result.WriteString(field)
+	return result.String(), nil
+}
+
+// resolveEnvRef returns the value of the contents of a single '${...}' reference
+func resolveEnvRef(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, "file:"):
+		path := strings.TrimPrefix(ref, "file:")
+		content, err := ioReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("Unable To Read Secret File %s: %s", path, err.Error())
+		}
+		// secrets are commonly written with a trailing newline which is never part of the value
+		return strings.TrimRight(string(content), "\r\n"), nil
+	case strings.Contains(ref, ":-"):
+		i := strings.Index(ref, ":-")
+		if value, err := lookupEnvVar(ref[:i]); err == nil {
+			return value, nil
+		}
+		return ref[i+2:], nil
+	default:
+		return lookupEnvVar(ref)
+	}
+}
+
+// lookupEnvVar returns the value of the given environment variable, 'HOSTNAME' is read from the OS
+func lookupEnvVar(name string) (string, error) {
+	switch {
+	case name == "HOSTNAME":
+		hostname, err := osHostname()
+		if err != nil {
+			return "", fmt.Errorf("Unable To Get Requested Hostname: %s", err.Error())
+		}
+		return hostname, nil
+	default:
+		if result := osGetenv(name); len(result) != 0 {
+			return result, nil
+		}
+		return "", fmt.Errorf("Env Var Not Found: %s", name)
+	}
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mockTestEnv mocks os.Getenv with a fixed set of variables
+func mockTestEnv(name string) string {
+	return map[string]string{"PORT": "9000", "TOKEN": "abc#123", "HOST": "localhost"}[name]
+}
+
+// TestInterpolate1 ensures references are expanded in keys and values, with numbers, defaults and secret files resolved
+func TestInterpolate1(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"ministub.yml": "version: 1.0\nservices:\n  api:\n    hostname: ${HOST}\n    port: ${PORT}\nrequests:\n  auth:\n    url: /${VERSION:-v1}/auth\n    method: get\n    headers:\n      Authorization: Bearer ${TOKEN}\n      X-Secret: \"${file:" + "secret.txt}\"\n      X-Port: '${PORT}'\n",
+		"secret.txt":   "hunter2\n",
+	})
+	defer os.RemoveAll(dir)
+	osGetenv = mockTestEnv
+
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(dir)
+
+	cfg, err := LoadFromFile(filepath.Join(dir, "ministub.yml"))
+	if err != nil {
+		t.Fatalf("Error Encountered Loading Config: %s", err.Error())
+	}
+
+	if service := cfg.Services["api"]; service.Hostname != "localhost" || service.Port != 9000 {
+		t.Errorf("Service Not Interpolated: %+v", service)
+	}
+
+	request := cfg.Requests["auth"]
+	if request.URL != "/v1/auth" {
+		t.Errorf("Default Not Used: %s", request.URL)
+	}
+	if request.Headers["Authorization"] != "Bearer abc#123" || request.Headers["X-Secret"] != "hunter2" || request.Headers["X-Port"] != "9000" {
+		t.Errorf("Headers Not Interpolated: %v", request.Headers)
+	}
+}
+
+// TestInterpolate2 ensures every missing reference across every file is reported together at its position
+func TestInterpolate2(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"a.yml": "version: 1.0\nservices:\n  api:\n    hostname: ${MISSING_HOST}\n    port: ${MISSING_PORT}\n",
+		"b.yml": "requests:\n  auth:\n    url: /auth\n    method: get\n    headers:\n      Authorization: Bearer ${MISSING_TOKEN}\n",
+	})
+	defer os.RemoveAll(dir)
+	osGetenv = mockTestEnv
+
+	_, err := LoadFromFile(dir)
+	errs, valid := err.(ValidationErrors)
+	if !valid || len(errs) != 3 {
+		t.Fatalf("Expected Three Errors, Got: %v", err)
+	}
+
+	if errs[0].File != filepath.Join(dir, "a.yml") || errs[0].Line != 4 || errs[0].Path != "services.api.hostname" {
+		t.Errorf("Missing Reference Not Located: %s", errs[0].Error())
+	}
+	if errs[2].File != filepath.Join(dir, "b.yml") || !strings.Contains(errs[2].Msg, "MISSING_TOKEN") {
+		t.Errorf("Missing Reference In Second File Not Reported: %s", errs[2].Error())
+	}
+}
+
+// TestInterpolate3 ensures decoding errors in an interpolated file are located at the line in the source
+func TestInterpolate3(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"ministub.yml": "version: 1.0\n\n\nservices:\n\n  api:\n    hostname: ${HOST}\n\n    port: ${HOST}\n",
+	})
+	defer os.RemoveAll(dir)
+	osGetenv = mockTestEnv
+
+	_, err := LoadFromFile(filepath.Join(dir, "ministub.yml"))
+	errs, valid := err.(ValidationErrors)
+	if !valid || len(errs) != 1 {
+		t.Fatalf("Expected One Error, Got: %v", err)
+	}
+
+	if errs[0].Line != 9 || errs[0].Path != "services.api.port" {
+		t.Errorf("Decoding Error Not Located In Source: %s", errs[0].Error())
+	}
+}
+
+// TestInterpolate4 ensures a service hostname of only '$VAR' is expanded, whilst '$$' escapes a reference anywhere
+func TestInterpolate4(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"ministub.yml": "version: 1.0\nservices:\n  api:\n    hostname: $HOST\n    port: 80\nrequests:\n  auth:\n    url: /auth\n    method: get\n    headers:\n      X-Host: $HOST\n      X-Template: $${HOST} is ${HOST}\n",
+	})
+	defer os.RemoveAll(dir)
+	osGetenv = mockTestEnv
+
+	cfg, err := LoadFromFile(filepath.Join(dir, "ministub.yml"))
+	if err != nil {
+		t.Fatalf("Error Encountered Loading Config: %s", err.Error())
+	}
+
+	if cfg.Services["api"].Hostname != "localhost" {
+		t.Errorf("Hostname Not Expanded: %s", cfg.Services["api"].Hostname)
+	}
+	if headers := cfg.Requests["auth"].Headers; headers["X-Host"] != "$HOST" || headers["X-Template"] != "${HOST} is localhost" {
+		t.Errorf("Unexpected Headers: %v", headers)
+	}
+}
+
+// TestInterpolate5 ensures a service hostname of '$HOSTNAME' is reported when the hostname cannot be read
+func TestInterpolate5(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"ministub.yml": "version: 1.0\nservices:\n  api:\n    hostname: $HOSTNAME\n    port: 80\n",
+	})
+	defer os.RemoveAll(dir)
+	osHostname = mockInvalidOsHostname
+	defer func() { osHostname = os.Hostname }()
+
+	_, err := LoadFromFile(filepath.Join(dir, "ministub.yml"))
+	errs, valid := err.(ValidationErrors)
+	if !valid || len(errs) != 1 || errs[0].Path != "services.api.hostname" || errs[0].Line != 4 {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+// TestLoadRawFromFile1 ensures references are kept when loading a config to be written back out
+func TestLoadRawFromFile1(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"ministub.yml": "version: 1.0\nservices:\n  api:\n    hostname: ${HOST}\n    port: 80\n",
+	})
+	defer os.RemoveAll(dir)
+	osGetenv = mockTestEnv
+
+	cfg, err := LoadRawFromFile(filepath.Join(dir, "ministub.yml"))
+	if err != nil {
+		t.Fatalf("Error Encountered Loading Config: %s", err.Error())
+	}
+	if cfg.Services["api"].Hostname != "${HOST}" {
+		t.Errorf("Reference Not Kept: %s", cfg.Services["api"].Hostname)
+	}
+}
+
+// TestExpandEnvRefs1 ensures '${VAR}' references are replaced anywhere within a value
+func TestExpandEnvRefs1(t *testing.T) {
+	osHostname = mockValidOsHostname
+	osGetenv = mockValidOsGetenv
+	defer func() { osHostname, osGetenv = os.Hostname, os.Getenv }()
+
+	value, err := expandEnvRefs("https://${ENV_VAR}/api?host=${HOSTNAME}")
+	if err != nil {
+		t.Errorf("Error Encountered Expanding References: %s", err.Error())
+	}
+	if value != "https://test_value/api?host=test_value" {
+		t.Errorf("Value Does Not Match Expected: %s", value)
+	}
+}
+
+// TestExpandEnvRefs2 ensures a missing '${VAR}' reference is returned as an error
+func TestExpandEnvRefs2(t *testing.T) {
+	osGetenv = mockInvalidOsGetenv
+	defer func() { osGetenv = os.Getenv }()
+
+	if _, err := expandEnvRefs("Bearer ${ENV_VAR}"); err == nil {
+		t.Errorf("Missing Env Var Returned No Error")
+	}
+}
+
+// TestExpandEnvRefs3 ensures '$${' is written as a literal '${' without being expanded
+func TestExpandEnvRefs3(t *testing.T) {
+	osGetenv = mockInvalidOsGetenv
+	defer func() { osGetenv = os.Getenv }()
+
+	value, err := expandEnvRefs("$${ENV_VAR} costs ${ENV_VAR:-5}")
+	if err != nil {
+		t.Errorf("Error Encountered Expanding References: %s", err.Error())
+	}
+	if value != "${ENV_VAR} costs 5" {
+		t.Errorf("Value Does Not Match Expected: %s", value)
+	}
+}
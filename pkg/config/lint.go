@@ -0,0 +1,181 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+/*Lint reports definitions in a validated config which are valid but are likely mistakes: services and requests no action
+uses, endpoints shadowed by others, undeclared path params, responses which are never returned and checks which never run.
+Every finding is returned as ValidationErrors, located in the same way as Validate */
+func Lint(cfg *Config) error {
+	errs := new(errorList)
+
+	lintUnused(cfg, errs)
+	lintEndpoints(cfg, errs)
+
+	for name, entry := range cfg.Requests {
+		if entry != nil && entry.ExpectedResponse != nil && len(entry.ExpectedResponse.Headers) > 0 {
+			errs.add(joinPath("requests", name, "expectedResponse", "headers"), "Expected Response Headers Are Never Checked, The Request Headers Are Compared Instead")
+		}
+	}
+
+	err := errs.result()
+	if found, valid := err.(ValidationErrors); valid {
+		found.locateIn(cfg)
+	}
+	return err
+}
+
+// lintUnused reports services and requests which no action references
+func lintUnused(cfg *Config, errs *errorList) {
+	services := make(map[string]bool)
+	requests := make(map[string]bool)
+	for _, actions := range configActions(cfg) {
+		for _, action := range *actions {
+			if action != nil && action.Request != nil {
+				services[action.Request.Target] = true
+				requests[action.Request.ID] = true
+			}
+		}
+	}
+
+	for name := range cfg.Services {
+		if !services[name] {
+			errs.add(joinPath("services", name), "Service Is Not Used By Any Action")
+		}
+	}
+	for name := range cfg.Requests {
+		if !requests[name] {
+			errs.add(joinPath("requests", name), "Request Is Not Used By Any Action")
+		}
+	}
+}
+
+// lintEndpoints reports shadowed endpoints, undeclared path params and responses which are never returned
+func lintEndpoints(cfg *Config, errs *errorList) {
+	urls := make([]string, 0, len(cfg.Endpoints))
+	for url := range cfg.Endpoints {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	for i, url := range urls {
+		for method, entry := range cfg.Endpoints[url] {
+			if entry == nil {
+				continue
+			}
+			path := joinPath("endpoints", url, method)
+
+			if entry.Params != nil {
+				params := pathParams(url)
+				for name := range entry.Params.Path {
+					if !params[name] {
+						errs.add(joinPath(path, "params", "path", name), "Path Param %s Does Not Appear In The URL", name)
+					}
+				}
+			}
+
+			lintResponses(path, entry, errs)
+
+			if len(pathParams(url)) == 0 {
+				continue
+			}
+
+			// URLs without params are matched first, a request to one without the method is rejected rather than passed on
+			for _, other := range urls {
+				if _, found := cfg.Endpoints[other][method]; !found && len(pathParams(other)) == 0 && patternCovers(url, other) {
+					errs.add(path, "Unreachable For %s, Which Does Not Define %s And Returns 405", other, method)
+				}
+			}
+
+			// the remaining URLs are matched in no particular order, so any overlap is served by either at random
+			for _, other := range urls[:i] {
+				if _, found := cfg.Endpoints[other][method]; !found || len(pathParams(other)) == 0 {
+					continue
+				}
+				switch {
+				case patternCovers(other, url):
+					errs.add(path, "Shadowed By %s, Which Matches Every Path It Does", other)
+				case patternCovers(url, other):
+					errs.add(joinPath("endpoints", other, method), "Shadowed By %s, Which Matches Every Path It Does", url)
+				case patternsOverlap(url, other):
+					errs.add(path, "Overlaps With %s, Requests Matching Both Are Served By Either", other)
+				}
+			}
+		}
+	}
+}
+
+// lintResponses reports weighted responses which are never returned, including those of SOAP operations
+func lintResponses(path string, entry *Endpoint, errs *errorList) {
+	if len(entry.Responses) > 1 {
+		for statusCode, resp := range entry.Responses {
+			if resp != nil && resp.Weight == 0 {
+				errs.add(joinPath(path, "responses", statusCode, "weight"), "Response Has Weight 0 And Is Never Returned")
+			}
+		}
+	}
+	for operation, opEntry := range entry.SOAP {
+		if opEntry != nil {
+			lintResponses(joinPath(path, "soap", operation), opEntry, errs)
+		}
+	}
+}
+
+// pathParams returns the names of the ':name' segments of an endpoint URL
+func pathParams(url string) map[string]bool {
+	params := make(map[string]bool)
+	for _, segment := range strings.Split(url, "/") {
+		if isPathParam(segment) {
+			params[segment[1:]] = true
+		}
+	}
+	return params
+}
+
+// isPathParam checks if a URL segment is a ':name' param, matching the path matcher of the API
+func isPathParam(segment string) bool {
+	return len(segment) > 1 && segment[0] == ':'
+}
+
+// patternCovers checks if every path matched by the inner URL is also matched by the outer URL
+func patternCovers(outer, inner string) bool {
+	outerSegments, innerSegments := strings.Split(outer, "/"), strings.Split(inner, "/")
+	if outer == inner || len(outerSegments) != len(innerSegments) {
+		return false
+	}
+	for i := range outerSegments {
+		switch {
+		case isPathParam(outerSegments[i]):
+			// params never match an empty segment
+			if len(innerSegments[i]) == 0 {
+				return false
+			}
+		case isPathParam(innerSegments[i]) || outerSegments[i] != innerSegments[i]:
+			return false
+		}
+	}
+	return true
+}
+
+// patternsOverlap checks if any path is matched by both URLs
+func patternsOverlap(a, b string) bool {
+	aSegments, bSegments := strings.Split(a, "/"), strings.Split(b, "/")
+	if len(aSegments) != len(bSegments) {
+		return false
+	}
+	for i := range aSegments {
+		aParam, bParam := isPathParam(aSegments[i]), isPathParam(bSegments[i])
+		switch {
+		case aParam && bParam:
+		case aParam || bParam:
+			if len(aSegments[i]) == 0 || len(bSegments[i]) == 0 {
+				return false
+			}
+		case aSegments[i] != bSegments[i]:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// TestLint1 ensures every kind of likely mistake is reported at its config path
+func TestLint1(t *testing.T) {
+	yamlUnmarshal = yaml.Unmarshal
+	yamlUnmarshalStrict = yaml.UnmarshalStrict
+	cfg, err := Parse([]byte(`version: 1.0
+services:
+    used:
+        hostname: localhost
+        port: 80
+    unused:
+        hostname: localhost
+        port: 80
+requests:
+    used:
+        url: /ping
+        method: get
+        headers:
+            Accept: application/json
+        expectedResponse:
+            statusCode: 200
+            headers:
+                Content-Type: application/json
+    unused:
+        url: /ping
+        method: get
+startupActions:
+    - request:
+          target: used
+          id: used
+endpoints:
+    /users/me:
+        get:
+            response: 200
+    /users/:id:
+        get:
+            params:
+                path:
+                    id:
+                        type: integer
+                    name:
+                        type: string
+            response: 200
+        post:
+            response: 201
+    /users/:name:
+        get:
+            responses:
+                200:
+                    weight: 100
+                404:
+                    weight: 0
+    /:type/admin:
+        get:
+            response: 200
+`))
+	if err != nil {
+		t.Fatalf("Error Encountered Parsing Config: %s", err.Error())
+	}
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("Error Encountered Validating Config: %s", err.Error())
+	}
+
+	errs, valid := Lint(cfg).(ValidationErrors)
+	if !valid {
+		t.Fatalf("No Problems Found")
+	}
+
+	expected := map[string]bool{
+		"services.unused: Service Is Not Used By Any Action":                                                                            false,
+		"requests.unused: Request Is Not Used By Any Action":                                                                            false,
+		"requests.used.expectedResponse.headers: Expected Response Headers Are Never Checked, The Request Headers Are Compared Instead": false,
+		"endpoints./users/:id.get.params.path.name: Path Param name Does Not Appear In The URL":                                         false,
+		"endpoints./users/:id.post: Unreachable For /users/me, Which Does Not Define post And Returns 405":                              false,
+		"endpoints./users/:name.get: Shadowed By /users/:id, Which Matches Every Path It Does":                                          false,
+		"endpoints./users/:name.get.responses.404.weight: Response Has Weight 0 And Is Never Returned":                                  false,
+		"endpoints./users/:name.get: Overlaps With /:type/admin, Requests Matching Both Are Served By Either":                           false,
+		"endpoints./users/:id.get: Overlaps With /:type/admin, Requests Matching Both Are Served By Either":                             false,
+	}
+	for _, entry := range errs {
+		msg := entry.Path + ": " + entry.Msg
+		if _, found := expected[msg]; !found {
+			t.Errorf("Unexpected Problem: %s", entry.Error())
+		}
+		expected[msg] = true
+		if entry.Line == 0 {
+			t.Errorf("Problem Not Located: %s", entry.Error())
+		}
+	}
+	for msg, found := range expected {
+		if !found {
+			t.Errorf("Problem Not Found: %s", msg)
+		}
+	}
+}
+
+// TestLint2 ensures a config without mistakes returns no problems
+func TestLint2(t *testing.T) {
+	yamlUnmarshal = yaml.Unmarshal
+	yamlUnmarshalStrict = yaml.UnmarshalStrict
+	cfg, err := Parse([]byte("version: 1.0\nendpoints:\n    /users/:id:\n        get:\n            response: 200\n    /users/:id/posts:\n        get:\n            response: 200\n    /users/:id/:\n        get:\n            response: 200\n"))
+	if err != nil {
+		t.Fatalf("Error Encountered Parsing Config: %s", err.Error())
+	}
+
+	if err := Lint(cfg); err != nil {
+		t.Errorf("Unexpected Problems: %s", err.Error())
+	}
+}
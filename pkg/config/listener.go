@@ -0,0 +1,68 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Listener represents a definition for a raw TCP or UDP stub listener
+type Listener struct {
+	Protocol string          `yaml:"protocol,omitempty"` // tcp or udp
+	Port     int             `yaml:"port,omitempty"`
+	Mode     string          `yaml:"mode,omitempty"`     // line or raw, defaults to line
+	Greeting string          `yaml:"greeting,omitempty"` // sent to TCP clients on connect
+	Rules    []*ListenerRule `yaml:"rules,omitempty"`
+}
+
+// ListenerRule represents a pattern to match incoming data against, and the scripted reply to send when it matches
+type ListenerRule struct {
+	Match    string    `yaml:"match,omitempty"`    // regex matched against a line or raw chunk
+	MatchHex string    `yaml:"matchHex,omitempty"` // hex byte sequence, '??' matches any byte
+	Reply    string    `yaml:"reply,omitempty"`
+	ReplyHex string    `yaml:"replyHex,omitempty"`
+	DelayMs  int       `yaml:"delayMs,omitempty"`
+	Close    bool      `yaml:"close,omitempty"`
+	Actions  []*Action `yaml:"actions,omitempty"`
+}
+
+// Pattern returns the identifier used for this rule in logs and stats
+func (r *ListenerRule) Pattern() string {
+	switch {
+	case len(r.Match) > 0:
+		return r.Match
+	case len(r.MatchHex) > 0:
+		return "hex:" + r.MatchHex
+	default:
+		return "*"
+	}
+}
+
+// ParseHexPattern converts a hex pattern such as "de ad ?? ef" into a byte sequence, where -1 represents a wildcard byte
+func ParseHexPattern(pattern string) ([]int, error) {
+	clean := strings.Join(strings.Fields(pattern), "")
+	if len(clean) == 0 || len(clean)%2 != 0 {
+		return nil, fmt.Errorf("Hex Pattern %s Must Contain An Even Number Of Digits", pattern)
+	}
+
+	result := make([]int, 0, len(clean)/2)
+	for i := 0; i < len(clean); i += 2 {
+		if clean[i:i+2] == "??" {
+			result = append(result, -1)
+			continue
+		}
+		value, err := strconv.ParseUint(clean[i:i+2], 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid Hex Byte %s In Pattern %s", clean[i:i+2], pattern)
+		}
+		result = append(result, int(value))
+	}
+
+	return result, nil
+}
+
+// DecodeHex converts a hex string such as "de ad be ef" into raw bytes, whitespace is ignored
+func DecodeHex(data string) ([]byte, error) {
+	return hex.DecodeString(strings.Join(strings.Fields(data), ""))
+}
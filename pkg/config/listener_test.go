@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+// TestParseHexPattern1 ensures a pattern with whitespace and wildcards is parsed correctly
+func TestParseHexPattern1(t *testing.T) {
+	result, err := ParseHexPattern("DE ad ??\tef")
+	if err != nil {
+		t.Errorf("Error Encountered Parsing Hex Pattern: %s", err.Error())
+	}
+
+	expected := []int{0xde, 0xad, -1, 0xef}
+	if len(result) != len(expected) {
+		t.Fatalf("Unexpected Pattern Length: %d", len(result))
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("Byte %d Does Not Match, Expected %d Got %d", i, expected[i], result[i])
+		}
+	}
+}
+
+// TestParseHexPattern2 ensures odd length and non-hex patterns are returned as errors
+func TestParseHexPattern2(t *testing.T) {
+	for _, pattern := range []string{"", "abc", "zz", "?a"} {
+		if _, err := ParseHexPattern(pattern); err == nil {
+			t.Errorf("Invalid Pattern '%s' Returned No Error", pattern)
+		}
+	}
+}
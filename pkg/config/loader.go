@@ -0,0 +1,319 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// loader merges a config spread across a root file, its includes and every config file in a directory
+type loader struct {
+	cfg         *Config
+	version     float32
+	interpolate bool              // expand '${...}' references whilst loading
+	loaded      map[string]bool   // absolute paths already loaded, so files included twice or in a cycle load once
+	origins     map[string]string // config path -> file defining it, to report conflicting definitions
+	watched     []string          // files loaded and directories globbed, in the order they were reached
+	errs        ValidationErrors
+}
+
+// newLoader creates a new instance of loader
+func newLoader(interpolate bool) *loader {
+	return &loader{
+		cfg:         &Config{positions: make(map[string]position)},
+		interpolate: interpolate,
+		loaded:      make(map[string]bool),
+		origins:     make(map[string]string),
+	}
+}
+
+// configFiles returns every '*.yml' and '*.yaml' file in the given directory, in order
+func configFiles(dir string) ([]string, error) {
+	files := make([]string, 0)
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("No Config Files Found In %s", dir)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// probeVersion reads only the version of a config, every file loaded together must share the same version
+func (l *loader) probeVersion(path string, content []byte) error {
+	var probe struct {
+		Version float32 `yaml:"version"`
+	}
+	if err := yamlUnmarshal(content, &probe); err != nil {
+		return fmt.Errorf("Unable To Unmarshal Cfg %s: %s", path, err.Error())
+	}
+
+	switch {
+	case probe.Version == 0:
+		return nil
+	case l.version == 0:
+		l.version = probe.Version
+		return nil
+	case probe.Version != l.version:
+		return fmt.Errorf("Version %v Of %s Does Not Match Version %v", probe.Version, path, l.version)
+	default:
+		return nil
+	}
+}
+
+// loadFile decodes a single file, merges it into the config then loads its includes
+func (l *loader) loadFile(path string, content []byte) error {
+	if absPath, err := filepath.Abs(path); err == nil {
+		if l.loaded[absPath] {
+			return nil
+		}
+		l.loaded[absPath] = true
+	}
+
+	content, positions, err := l.prepare(path, content)
+	if err != nil {
+		return err
+	}
+
+	fileCfg, err := l.decode(path, content, positions)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	resolveBodyFiles(fileCfg, func(string) string { return dir })
+	l.merge(fileCfg, path, positions)
+
+	for _, pattern := range fileCfg.Include {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("Invalid Include %s In %s: %s", pattern, path, err.Error())
+		}
+		if !strings.ContainsAny(pattern, "*?[") {
+			if len(matches) == 0 {
+				return fmt.Errorf("Include %s In %s Not Found", pattern, path)
+			}
+		} else if dir := filepath.Dir(pattern); !strings.ContainsAny(dir, "*?[") {
+			// files added to or removed from a globbed directory change its modification time
+			l.watched = append(l.watched, dir)
+		}
+
+		for _, match := range matches {
+			includeContent, err := ioReadFile(match)
+			if err != nil {
+				return fmt.Errorf("Unable To Read File %s: %s", match, err.Error())
+			}
+			if err := l.loadFile(match, includeContent); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// prepare records a file to watch, returning its content with references expanded and the positions within it
+func (l *loader) prepare(path string, content []byte) ([]byte, map[string]position, error) {
+	l.watched = append(l.watched, path)
+
+	positions := nodePositions(content)
+	for key, pos := range positions {
+		pos.File = path
+		positions[key] = pos
+	}
+
+	// missing references are collected from every file, so they can all be reported together
+	if l.interpolate {
+		var err error
+		content, err = interpolate(content)
+		if errs, valid := err.(ValidationErrors); valid {
+			errs.locate(path, positions)
+			l.errs = append(l.errs, errs...)
+		}
+	}
+
+	if err := l.probeVersion(path, content); err != nil {
+		return nil, nil, err
+	}
+	return content, positions, nil
+}
+
+/*decode decodes a config with the strictness of the loaded version. When the content was rewritten, by interpolation or
+merging, decoding errors are located by the config path on their line then by its position in the source */
+func (l *loader) decode(path string, content []byte, positions map[string]position) (*Config, error) {
+	unmarshal := yamlUnmarshal
+	if l.version >= 2.0 {
+		unmarshal = yamlUnmarshalStrict
+	}
+
+	cfg := new(Config)
+	if err := unmarshal(content, &cfg); err != nil {
+		err = decodeErrors(err)
+		if errs, valid := err.(ValidationErrors); valid {
+			rewritten := nodePositions(content)
+			for key, pos := range rewritten {
+				if source, found := positions[key]; !found || source.Line != pos.Line {
+					errs.relocate(path, rewritten)
+					break
+				}
+			}
+			errs.locate(path, positions)
+		}
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// define records the file defining a config path, returning false and recording an error if it is already defined
+func (l *loader) define(path, file string, positions map[string]position) bool {
+	if origin, found := l.origins[path]; found {
+		conflict := &ValidationError{Path: path, Msg: fmt.Sprintf("Conflicts With Definition In %s", origin)}
+		if pos, found := l.cfg.positions[path]; found {
+			conflict.Msg = fmt.Sprintf("Conflicts With Definition At %s:%d:%d", pos.File, pos.Line, pos.Column)
+		}
+		ValidationErrors{conflict}.locate(file, positions)
+		l.errs = append(l.errs, conflict)
+		return false
+	}
+
+	l.origins[path] = file
+	if pos, found := positions[path]; found {
+		l.cfg.positions[path] = pos
+	}
+	return true
+}
+
+// merge adds the definitions of a single file to the config, named definitions may only be made by one file
+func (l *loader) merge(src *Config, file string, positions map[string]position) {
+	dst := l.cfg
+
+	// positions below each definition are only kept for definitions which were merged
+	keep := func(prefix string) {
+		for key, pos := range positions {
+			if strings.HasPrefix(key, prefix+".") || strings.HasPrefix(key, prefix+"[") {
+				dst.positions[key] = pos
+			}
+		}
+	}
+
+	if src.Version != 0 {
+		dst.Version = src.Version
+	}
+
+	for name, entry := range src.Services {
+		if path := joinPath("services", name); l.define(path, file, positions) {
+			if dst.Services == nil {
+				dst.Services = make(map[string]*Service)
+			}
+			dst.Services[name] = entry
+			keep(path)
+		}
+	}
+
+	for name, entry := range src.Requests {
+		if path := joinPath("requests", name); l.define(path, file, positions) {
+			if dst.Requests == nil {
+				dst.Requests = make(map[string]*Request)
+			}
+			dst.Requests[name] = entry
+			keep(path)
+		}
+	}
+
+	for name, entry := range src.Listeners {
+		if path := joinPath("listeners", name); l.define(path, file, positions) {
+			if dst.Listeners == nil {
+				dst.Listeners = make(map[string]*Listener)
+			}
+			dst.Listeners[name] = entry
+			keep(path)
+		}
+	}
+
+	for url, methods := range src.Endpoints {
+		for method, entry := range methods {
+			if path := joinPath("endpoints", url, method); l.define(path, file, positions) {
+				if dst.Endpoints == nil {
+					dst.Endpoints = make(map[string]map[string]*Endpoint)
+				}
+				if dst.Endpoints[url] == nil {
+					dst.Endpoints[url] = make(map[string]*Endpoint)
+				}
+				dst.Endpoints[url][method] = entry
+				keep(path)
+			}
+		}
+	}
+
+	if src.Proxy != nil && l.define("proxy", file, positions) {
+		dst.Proxy = src.Proxy
+		keep("proxy")
+	}
+
+	if src.Admin != nil && l.define("admin", file, positions) {
+		dst.Admin = src.Admin
+		keep("admin")
+	}
+
+	if src.Startup != nil && l.define("startup", file, positions) {
+		dst.Startup = src.Startup
+		keep("startup")
+	}
+
+	// startup actions from every file run in the order the files were loaded
+	offset := len(dst.StartupActions)
+	for key, pos := range positions {
+		segments := strings.SplitN(key, ".", 3)
+		if len(segments) < 2 || segments[0] != "startupActions" {
+			continue
+		}
+		if i, err := strconv.Atoi(segments[1]); err == nil {
+			segments[1] = strconv.Itoa(offset + i)
+			dst.positions[strings.Join(segments, ".")] = pos
+		}
+	}
+	dst.StartupActions = append(dst.StartupActions, src.StartupActions...)
+
+	for _, key := range []string{"version", "services", "requests", "listeners", "endpoints", "startupActions"} {
+		if _, found := dst.positions[key]; !found {
+			if pos, found := positions[key]; found {
+				dst.positions[key] = pos
+			}
+		}
+	}
+}
+
+// resolveBodyFiles resolves relative 'bodyFile' paths against the directory of the file declaring them, found from their config path
+func resolveBodyFiles(cfg *Config, dirFor func(path string) string) {
+	var resolve func(entry *Endpoint, path string)
+	resolve = func(entry *Endpoint, path string) {
+		if entry == nil {
+			return
+		}
+		for statusCode, resp := range entry.Responses {
+			if resp != nil && len(resp.BodyFile) > 0 && !filepath.IsAbs(resp.BodyFile) {
+				resp.BodyFile = filepath.Join(dirFor(joinPath(path, "responses", statusCode, "bodyFile")), resp.BodyFile)
+			}
+		}
+		for operation, opEntry := range entry.SOAP {
+			resolve(opEntry, joinPath(path, "soap", operation))
+		}
+	}
+
+	for url, methods := range cfg.Endpoints {
+		for method, entry := range methods {
+			resolve(entry, joinPath("endpoints", url, method))
+		}
+	}
+}
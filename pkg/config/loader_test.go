@@ -0,0 +1,98 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// writeTestFiles writes the given files into a new temp dir, returning its path
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	osStat = os.Stat
+	ioReadFile = ioutil.ReadFile
+	yamlUnmarshal = yaml.Unmarshal
+	yamlUnmarshalStrict = yaml.UnmarshalStrict
+
+	dir, err := ioutil.TempDir("", "ministub")
+	if err != nil {
+		t.Fatalf("Unable To Create Temp Dir: %s", err.Error())
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Unable To Create Dir: %s", err.Error())
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Unable To Write File: %s", err.Error())
+		}
+	}
+	return dir
+}
+
+// TestLoadFromFile6 ensures included files are merged, resolving globs and bodyFile paths against the declaring file
+func TestLoadFromFile6(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"ministub.yml":          "version: 1.0\ninclude:\n  - services/*.yml\n",
+		"services/billing.yml":  "endpoints:\n  /billing:\n    get:\n      responses:\n        200:\n          weight: 100\n          bodyFile: bodies/billing.json\n",
+		"services/accounts.yml": "include:\n  - ../ministub.yml\nendpoints:\n  /accounts:\n    get:\n      responses:\n        200:\n          weight: 100\n",
+	})
+	defer os.RemoveAll(dir)
+
+	cfg, err := LoadFromFile(filepath.Join(dir, "ministub.yml"))
+	if err != nil {
+		t.Fatalf("Error Encountered Loading Includes: %s", err.Error())
+	}
+
+	if cfg.Version != 1.0 || len(cfg.Endpoints) != 2 || len(cfg.Include) != 0 {
+		t.Errorf("Includes Not Merged: %+v", cfg)
+	}
+	if bodyFile := cfg.Endpoints["/billing"]["get"].Responses[200].BodyFile; bodyFile != filepath.Join(dir, "services", "bodies", "billing.json") {
+		t.Errorf("Body File Not Resolved Against Including File: %s", bodyFile)
+	}
+	if err := Validate(cfg); err != nil {
+		t.Errorf("Error Encountered Validating Merged Config: %s", err.Error())
+	}
+}
+
+// TestLoadFromFile7 ensures every config file in a directory is loaded, with conflicting endpoints reported
+func TestLoadFromFile7(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"a.yml":  "version: 1.0\nendpoints:\n  /test:\n    get:\n      responses:\n        200:\n          weight: 100\n",
+		"b.yaml": "endpoints:\n  /test:\n    post:\n      responses:\n        200:\n          weight: 100\n    get:\n      responses:\n        404:\n          weight: 100\n",
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := LoadFromFile(dir)
+	errs, valid := err.(ValidationErrors)
+	if !valid || len(errs) != 1 {
+		t.Fatalf("Expected One Conflict, Got: %v", err)
+	}
+
+	if errs[0].File != filepath.Join(dir, "b.yaml") || errs[0].Line != 7 || errs[0].Path != "endpoints./test.get" {
+		t.Errorf("Conflict Not Located At Second Definition: %s", errs[0].Error())
+	}
+	if !strings.Contains(errs[0].Msg, filepath.Join(dir, "a.yml")+":4:5") {
+		t.Errorf("Conflict Does Not Reference First Definition: %s", errs[0].Msg)
+	}
+}
+
+// TestLoadFromFile8 ensures missing includes, mismatched versions and empty directories return errors
+func TestLoadFromFile8(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"missing.yml":  "version: 1.0\ninclude:\n  - other.yml\n",
+		"version.yml":  "version: 1.0\ninclude:\n  - nested/*.yml\n",
+		"nested/2.yml": "version: 2.0\n",
+		"empty/README": "",
+	})
+	defer os.RemoveAll(dir)
+
+	for _, path := range []string{"missing.yml", "version.yml", "empty"} {
+		if _, err := LoadFromFile(filepath.Join(dir, path)); err == nil {
+			t.Errorf("No Error Encountered Loading %s", path)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// tags which change how an overlay value is merged
+const (
+	replaceTag = "!replace" // replaces the base value rather than merging into it
+	deleteTag  = "!delete"  // removes the key from the base
+)
+
+// ProfilePath returns the overlay for a named profile, 'ministub.ci.yml' for 'ministub.yml', or 'profiles/ci.yml' within a directory
+func ProfilePath(base, profile string, isDir bool) string {
+	if isDir {
+		return filepath.Join(base, "profiles", fmt.Sprintf("%s.yml", profile))
+	}
+	ext := filepath.Ext(base)
+	return fmt.Sprintf("%s.%s%s", strings.TrimSuffix(base, ext), profile, ext)
+}
+
+/*applyOverlays deep-merges each overlay file into the loaded config in order. Mappings are merged by key, lists are
+appended to, and any other value replaces the base value */
+func (l *loader) applyOverlays(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	base, err := yaml.Marshal(l.cfg)
+	if err != nil {
+		return fmt.Errorf("Unable To Marshal Cfg: %s", err.Error())
+	}
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(base, &root); err != nil {
+		return fmt.Errorf("Unable To Unmarshal Cfg: %s", err.Error())
+	}
+	// an empty base is marshalled as a flow mapping
+	root.Content[0].Style = 0
+
+	for _, path := range paths {
+		if _, err := osStat(path); err != nil {
+			return fmt.Errorf("File %s Not Found", path)
+		}
+		content, err := ioReadFile(path)
+		if err != nil {
+			return fmt.Errorf("Unable To Read File %s: %s", path, err.Error())
+		}
+
+		content, positions, err := l.prepare(path, content)
+		if err != nil {
+			return err
+		}
+
+		var overlay yamlv3.Node
+		if err := yamlv3.Unmarshal(content, &overlay); err != nil {
+			return fmt.Errorf("Unable To Unmarshal Cfg %s: %s", path, err.Error())
+		}
+		if len(overlay.Content) == 0 {
+			continue
+		}
+		if overlay.Content[0].Kind != yamlv3.MappingNode {
+			return fmt.Errorf("Overlay %s Is Not A Mapping", path)
+		}
+
+		mergeNodes(root.Content[0], overlay.Content[0])
+		for key, pos := range positions {
+			l.cfg.positions[key] = pos
+		}
+	}
+
+	merged, err := yamlv3.Marshal(&root)
+	if err != nil {
+		return fmt.Errorf("Unable To Marshal Cfg: %s", err.Error())
+	}
+
+	cfg, err := l.decode(l.cfg.file, merged, l.cfg.positions)
+	if err != nil {
+		return err
+	}
+
+	// body files of the base are already absolute, those of an overlay are found from its position
+	resolveBodyFiles(cfg, func(path string) string {
+		for ; len(path) > 0; path = parentPath(path) {
+			if pos, found := l.cfg.positions[path]; found && len(pos.File) > 0 {
+				return filepath.Dir(pos.File)
+			}
+		}
+		return "."
+	})
+
+	cfg.positions = l.cfg.positions
+	l.cfg = cfg
+	return nil
+}
+
+// mergeNodes deep-merges the overlay into the base mapping, honouring the '!replace' and '!delete' tags
+func mergeNodes(base, overlay *yamlv3.Node) {
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+
+		index := -1
+		for j := 0; j+1 < len(base.Content); j += 2 {
+			if base.Content[j].Value == key.Value {
+				index = j
+				break
+			}
+		}
+
+		switch {
+		case value.Tag == deleteTag:
+			if index >= 0 {
+				base.Content = append(base.Content[:index], base.Content[index+2:]...)
+			}
+		case index < 0:
+			base.Content = append(base.Content, key, stripTags(value))
+		case value.Tag == replaceTag:
+			base.Content[index+1] = stripTags(value)
+		case value.Kind == yamlv3.MappingNode && base.Content[index+1].Kind == yamlv3.MappingNode:
+			mergeNodes(base.Content[index+1], value)
+		case value.Kind == yamlv3.SequenceNode && base.Content[index+1].Kind == yamlv3.SequenceNode:
+			for _, item := range value.Content {
+				base.Content[index+1].Content = append(base.Content[index+1].Content, stripTags(item))
+			}
+		default:
+			base.Content[index+1] = stripTags(value)
+		}
+	}
+}
+
+// stripTags removes the merge tags from a value added from an overlay, dropping any keys marked for deletion
+func stripTags(node *yamlv3.Node) *yamlv3.Node {
+	if node.Tag == replaceTag {
+		node.Tag = ""
+		node.Style &^= yamlv3.TaggedStyle
+	}
+
+	content := make([]*yamlv3.Node, 0, len(node.Content))
+	for i := 0; i < len(node.Content); i++ {
+		if node.Kind == yamlv3.MappingNode && i+1 < len(node.Content) {
+			if node.Content[i+1].Tag == deleteTag {
+				i++
+				continue
+			}
+			content = append(content, node.Content[i], stripTags(node.Content[i+1]))
+			i++
+			continue
+		}
+		content = append(content, stripTags(node.Content[i]))
+	}
+	node.Content = content
+
+	return node
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testOverlayBase is a base config for overlay tests
+const testOverlayBase = `version: 1.0
+services:
+    api:
+        hostname: localhost
+        port: 8000
+requests:
+    ping:
+        url: /ping
+        method: get
+startupActions:
+    - delay: 1
+endpoints:
+    /a:
+        get:
+            responses:
+                200:
+                    weight: 100
+    /debug:
+        get:
+            responses:
+                200:
+                    weight: 100
+`
+
+// TestApplyOverlays1 ensures overlays deep-merge mappings, append lists and honour the replace and delete tags
+func TestApplyOverlays1(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"ministub.yml":    testOverlayBase,
+		"ministub.ci.yml": "services:\n  api:\n    port: 9000\nstartupActions:\n  - request:\n      target: api\n      id: ping\nendpoints:\n  /debug: !delete\n  /a:\n    get:\n      responses:\n        201:\n          weight: 100\n          bodyFile: ci.json\n",
+		"demo.yml":        "startupActions: !replace\n  - delay: 5\nendpoints:\n  /a:\n    get:\n      responses: !replace\n        200:\n          weight: 100\n",
+	})
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(dir, "ministub.yml")
+	cfg, err := LoadFromFiles([]string{base, ProfilePath(base, "ci", false)})
+	if err != nil {
+		t.Fatalf("Error Encountered Loading Overlay: %s", err.Error())
+	}
+
+	if cfg.Services["api"].Port != 9000 || cfg.Services["api"].Hostname != "localhost" {
+		t.Errorf("Service Not Merged: %+v", cfg.Services["api"])
+	}
+	if len(cfg.StartupActions) != 2 || cfg.StartupActions[1].Request == nil {
+		t.Errorf("Startup Actions Not Appended: %d", len(cfg.StartupActions))
+	}
+	if _, found := cfg.Endpoints["/debug"]; found {
+		t.Errorf("Endpoint Not Deleted")
+	}
+	if responses := cfg.Endpoints["/a"]["get"].Responses; len(responses) != 2 || responses[201].BodyFile != filepath.Join(dir, "ci.json") {
+		t.Errorf("Responses Not Merged: %+v", responses)
+	}
+
+	cfg, err = LoadFromFiles([]string{base, filepath.Join(dir, "demo.yml")})
+	if err != nil {
+		t.Fatalf("Error Encountered Loading Overlay: %s", err.Error())
+	}
+	if len(cfg.StartupActions) != 1 || cfg.StartupActions[0].Delay.Seconds != 5 {
+		t.Errorf("Startup Actions Not Replaced: %+v", cfg.StartupActions)
+	}
+	if len(cfg.Files()) != 2 {
+		t.Errorf("Overlay Not Watched: %v", cfg.Files())
+	}
+}
+
+// TestApplyOverlays2 ensures errors within an overlay are located in the overlay
+func TestApplyOverlays2(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"ministub.yml":    testOverlayBase,
+		"ministub.ci.yml": "services:\n  api:\n    port: 70000\n",
+		"version.yml":     "version: 2.0\n",
+	})
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(dir, "ministub.yml")
+	cfg, err := LoadFromFiles([]string{base, ProfilePath(base, "ci", false)})
+	if err != nil {
+		t.Fatalf("Error Encountered Loading Overlay: %s", err.Error())
+	}
+
+	errs, valid := Validate(cfg).(ValidationErrors)
+	if !valid || len(errs) != 1 {
+		t.Fatalf("Expected One Error, Got: %v", errs)
+	}
+	if errs[0].File != filepath.Join(dir, "ministub.ci.yml") || errs[0].Line != 3 {
+		t.Errorf("Error Not Located In Overlay: %s", errs[0].Error())
+	}
+
+	if _, err := LoadFromFiles([]string{base, filepath.Join(dir, "version.yml")}); err == nil {
+		t.Errorf("Overlay With Different Version Returned No Error")
+	}
+	if _, err := LoadFromFiles([]string{base, filepath.Join(dir, "missing.yml")}); err == nil {
+		t.Errorf("Missing Overlay Returned No Error")
+	}
+}
+
+// TestProfilePath1 ensures profiles are found next to a base file, or in the profiles directory of a base directory
+func TestProfilePath1(t *testing.T) {
+	if path := ProfilePath("cfg/ministub.yml", "ci", false); path != "cfg/ministub.ci.yml" {
+		t.Errorf("Unexpected Profile Path: %s", path)
+	}
+	if path := ProfilePath("cfg", "ci", true); path != filepath.Join("cfg", "profiles", "ci.yml") {
+		t.Errorf("Unexpected Profile Path: %s", path)
+	}
+}
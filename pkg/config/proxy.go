@@ -0,0 +1,7 @@
+package config
+
+// Proxy represents the upstream services requests matching no endpoint are forwarded to
+type Proxy struct {
+	Target string            `yaml:"target,omitempty"` // used when no route matches, e.g. http://localhost:9000
+	Routes map[string]string `yaml:"routes,omitempty"` // path prefix -> upstream, the longest matching prefix wins
+}
@@ -2,9 +2,19 @@ package config
 
 // Response represents a response from request
 type Response struct {
-	StatusCode int                      `yaml:"statusCode"`
-	Body       map[string]interface{}   `yaml:"body"`
-	Headers    map[string]string        `yaml:"headers"`
-	Weight     int                      `yaml:"weight"`
-	Actions    []map[string]interface{} `yaml:"actions"`
+	StatusCode int                    `yaml:"statusCode,omitempty"`
+	Body       map[string]interface{} `yaml:"body,omitempty"`
+	RawBody    string                 `yaml:"rawBody,omitempty"`  // text/template rendered in place of body
+	BodyFile   string                 `yaml:"bodyFile,omitempty"` // file holding a text/template rendered in place of body
+	Fault      *Fault                 `yaml:"fault,omitempty"`
+	Headers    map[string]string      `yaml:"headers,omitempty"`
+	Weight     int                    `yaml:"weight,omitempty"`
+	Actions    []*Action              `yaml:"actions,omitempty"`
+}
+
+// Fault represents a SOAP 1.1 fault returned in place of a response body
+type Fault struct {
+	Code   string `yaml:"code,omitempty"` // e.g. soap:Server
+	String string `yaml:"string,omitempty"`
+	Detail string `yaml:"detail,omitempty"` // raw XML placed inside the detail element
 }
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// envRefSchema matches a string holding a '${...}' reference, accepted in place of any number or boolean
+var envRefSchema = map[string]interface{}{"type": "string", "pattern": `\$\{[^}]+\}`}
+
+// schemaAlternative is implemented by types which unmarshal from another form as well as their fields
+type schemaAlternative interface {
+	schemaAlternatives() []map[string]interface{}
+}
+
+// schemaGenerator builds a JSON Schema from the config types, each struct is defined once and referenced by name
+type schemaGenerator struct {
+	defs map[string]interface{}
+}
+
+// Schema returns a JSON Schema describing every field of a config file, generated from the config types
+func Schema() map[string]interface{} {
+	gen := &schemaGenerator{defs: make(map[string]interface{})}
+	strict := gen.ref(reflect.TypeOf(Config{}))
+
+	// version 1 configs are decoded leniently, so other top-level keys such as a list of YAML anchors are ignored
+	properties := make(map[string]interface{})
+	for name, property := range gen.defs["Config"].(map[string]interface{})["properties"].(map[string]interface{}) {
+		properties[name] = property
+	}
+	properties["version"] = map[string]interface{}{"type": "number", "exclusiveMaximum": 2}
+	lenient := map[string]interface{}{
+		"type":                 "object",
+		"title":                "Config (Version 1)",
+		"properties":           properties,
+		"additionalProperties": true,
+	}
+
+	return map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   "ministub config",
+		"anyOf":   []interface{}{strict, lenient},
+		"$defs":   gen.defs,
+	}
+}
+
+// typeSchema returns the schema for a single Go type
+func (g *schemaGenerator) typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		// pointers, maps and slices may be left empty with null
+		return map[string]interface{}{"anyOf": []interface{}{g.typeSchema(t.Elem()), map[string]interface{}{"type": "null"}}}
+	case reflect.Struct:
+		return g.ref(t)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"type": "boolean"}, envRefSchema}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"type": "integer"}, envRefSchema}}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"type": "number"}, envRefSchema}}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": []interface{}{"array", "null"}, "items": g.typeSchema(t.Elem())}
+	case reflect.Map:
+		schema := map[string]interface{}{"type": []interface{}{"object", "null"}, "additionalProperties": g.typeSchema(t.Elem())}
+		if t.Key().Kind() != reflect.String {
+			// YAML keys such as status codes are numbers, which are strings once in JSON
+			schema["propertyNames"] = map[string]interface{}{"pattern": "^[0-9]+$"}
+		}
+		return schema
+	default:
+		// interface{} values, such as bodies, may hold anything
+		return map[string]interface{}{}
+	}
+}
+
+// ref returns a reference to the definition of a struct type, defining it on first use
+func (g *schemaGenerator) ref(t reflect.Type) map[string]interface{} {
+	if _, found := g.defs[t.Name()]; !found {
+		// recorded before generating so recursive types, such as SOAP operations, reference themselves
+		g.defs[t.Name()] = nil
+		g.defs[t.Name()] = g.structSchema(t)
+	}
+	return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+}
+
+// structSchema returns the schema of a struct, with a property for each field named by its YAML tag
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if len(field.PkgPath) > 0 || name == "-" {
+			continue
+		}
+		if len(name) == 0 {
+			name = strings.ToLower(field.Name)
+		}
+		properties[name] = g.typeSchema(field.Type)
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"title":                t.Name(),
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+
+	if alternative, valid := reflect.New(t).Interface().(schemaAlternative); valid {
+		anyOf := []interface{}{schema}
+		for _, entry := range alternative.schemaAlternatives() {
+			anyOf = append(anyOf, entry)
+		}
+		return map[string]interface{}{"title": t.Name(), "anyOf": anyOf}
+	}
+	return schema
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// schemaErrors validates a JSON value against the subset of JSON Schema generated by Schema, returning every violation
+func schemaErrors(root, schema map[string]interface{}, value interface{}, path string) []string {
+	if ref, found := schema["$ref"].(string); found {
+		return schemaErrors(root, root["$defs"].(map[string]interface{})[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{}), value, path)
+	}
+
+	if anyOf, found := schema["anyOf"].([]interface{}); found {
+		for _, entry := range anyOf {
+			if len(schemaErrors(root, entry.(map[string]interface{}), value, path)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: Matches No Alternative", path)}
+	}
+
+	// a list of types is valid when any one of them is
+	if types, found := schema["type"].([]interface{}); found {
+		var errs []string
+		for i, entry := range types {
+			alternative := make(map[string]interface{}, len(schema))
+			for k, v := range schema {
+				alternative[k] = v
+			}
+			alternative["type"] = entry
+			found := schemaErrors(root, alternative, value, path)
+			if len(found) == 0 {
+				return nil
+			}
+			if i == 0 {
+				errs = found
+			}
+		}
+		return errs
+	}
+
+	errs := make([]string, 0)
+	switch schema["type"] {
+	case "object":
+		object, valid := value.(map[string]interface{})
+		if !valid {
+			return []string{fmt.Sprintf("%s: Expected Object", path)}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for key, entry := range object {
+			if names, found := schema["propertyNames"].(map[string]interface{}); found && !regexp.MustCompile(names["pattern"].(string)).MatchString(key) {
+				errs = append(errs, fmt.Sprintf("%s.%s: Invalid Property Name", path, key))
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if property, found := properties[key]; found {
+					errs = append(errs, schemaErrors(root, property.(map[string]interface{}), entry, path+"."+key)...)
+				} else if !additional {
+					errs = append(errs, fmt.Sprintf("%s.%s: Unknown Property", path, key))
+				}
+			case map[string]interface{}:
+				errs = append(errs, schemaErrors(root, additional, entry, path+"."+key)...)
+			}
+		}
+	case "array":
+		array, valid := value.([]interface{})
+		if !valid {
+			return []string{fmt.Sprintf("%s: Expected Array", path)}
+		}
+		for i, entry := range array {
+			errs = append(errs, schemaErrors(root, schema["items"].(map[string]interface{}), entry, fmt.Sprintf("%s.%d", path, i))...)
+		}
+	case "string":
+		str, valid := value.(string)
+		if !valid {
+			return []string{fmt.Sprintf("%s: Expected String", path)}
+		}
+		if pattern, found := schema["pattern"].(string); found && !regexp.MustCompile(pattern).MatchString(str) {
+			errs = append(errs, fmt.Sprintf("%s: Does Not Match %s", path, pattern))
+		}
+	case "integer":
+		if number, valid := value.(float64); !valid || number != float64(int64(number)) {
+			errs = append(errs, fmt.Sprintf("%s: Expected Integer", path))
+		}
+	case "number":
+		number, valid := value.(float64)
+		if !valid {
+			return []string{fmt.Sprintf("%s: Expected Number", path)}
+		}
+		if maximum, found := schema["exclusiveMaximum"].(float64); found && number >= maximum {
+			errs = append(errs, fmt.Sprintf("%s: Not Below %v", path, maximum))
+		}
+	case "boolean":
+		if _, valid := value.(bool); !valid {
+			errs = append(errs, fmt.Sprintf("%s: Expected Boolean", path))
+		}
+	case "null":
+		if value != nil {
+			errs = append(errs, fmt.Sprintf("%s: Expected Null", path))
+		}
+	}
+	return errs
+}
+
+// yamlToJSON decodes YAML into the values the same document would decode to as JSON
+func yamlToJSON(t *testing.T, content []byte) interface{} {
+	var raw interface{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		t.Fatalf("Unable To Unmarshal YAML: %s", err.Error())
+	}
+	data, err := json.Marshal(jsonKeys(raw))
+	if err != nil {
+		t.Fatalf("Unable To Marshal JSON: %s", err.Error())
+	}
+
+	var result interface{}
+	json.Unmarshal(data, &result)
+	return result
+}
+
+// jsonKeys converts the maps decoded from YAML into maps with string keys
+func jsonKeys(input interface{}) interface{} {
+	switch value := input.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			result[fmt.Sprint(k)] = jsonKeys(v)
+		}
+		return result
+	case []interface{}:
+		for i, v := range value {
+			value[i] = jsonKeys(v)
+		}
+	}
+	return input
+}
+
+// testSchema returns the generated schema as it is once marshalled to JSON
+func testSchema(t *testing.T) map[string]interface{} {
+	data, err := json.Marshal(Schema())
+	if err != nil {
+		t.Fatalf("Unable To Marshal Schema: %s", err.Error())
+	}
+	var schema map[string]interface{}
+	json.Unmarshal(data, &schema)
+	return schema
+}
+
+// TestSchema1 ensures every example config is valid against the schema
+func TestSchema1(t *testing.T) {
+	schema := testSchema(t)
+
+	paths, _ := filepath.Glob("../../examples/*.yml")
+	if len(paths) == 0 {
+		t.Fatalf("No Example Configs Found")
+	}
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Unable To Read Example: %s", err.Error())
+		}
+		for _, err := range schemaErrors(schema, schema, yamlToJSON(t, content), filepath.Base(path)) {
+			t.Errorf("Example Invalid: %s", err)
+		}
+	}
+}
+
+// TestSchema2 ensures misspelt fields, wrong types, v1 and v2 action forms and v1 top-level keys are checked by the schema
+func TestSchema2(t *testing.T) {
+	schema := testSchema(t)
+
+	valid := "version: 2.0\nservices:\n  api:\n    hostname: localhost\n    port: ${PORT}\nstartupActions:\n  - delay: 5\n  - delay:\n      seconds: 1\nendpoints:\n  /a:\n    get:\n      responses:\n        200:\n          weight: 100\n          body:\n            anything: [1, true]\n"
+	if errs := schemaErrors(schema, schema, yamlToJSON(t, []byte(valid)), ""); len(errs) > 0 {
+		t.Errorf("Valid Config Rejected: %v", errs)
+	}
+	if errs := schemaErrors(schema, schema, yamlToJSON(t, []byte("version: 1.0\nanchors:\n  - &WORLD world\n")), ""); len(errs) > 0 {
+		t.Errorf("Version 1 Anchors Rejected: %v", errs)
+	}
+
+	for _, invalid := range []string{
+		"services:\n  api:\n    hostnme: localhost\n",
+		"services:\n  api:\n    port: eighty\n",
+		"endpoints:\n  /a:\n    get:\n      responses:\n        ok:\n          weight: 100\n",
+		"startupActions:\n  - delay: soon\n",
+		"version: 2.0\nanchors:\n  - &WORLD world\n",
+	} {
+		if errs := schemaErrors(schema, schema, yamlToJSON(t, []byte(invalid)), ""); len(errs) == 0 {
+			t.Errorf("Invalid Config Accepted: %s", invalid)
+		}
+	}
+}
+
+// TestSchema3 ensures the published schema matches the schema generated from the config types
+func TestSchema3(t *testing.T) {
+	published, err := ioutil.ReadFile("../../schema/ministub.schema.json")
+	if err != nil {
+		t.Fatalf("Unable To Read Published Schema: %s", err.Error())
+	}
+
+	generated, _ := json.MarshalIndent(Schema(), "", "  ")
+	if string(published) != string(generated)+"\n" {
+		t.Errorf("Published Schema Is Out Of Date, Regenerate With 'make schema'")
+	}
+}
@@ -2,6 +2,6 @@ package config
 
 // Service represents the cfg definition for a microservice
 type Service struct {
-	Hostname string `yaml:"hostname"`
-	Port     int    `yaml:"port"`
+	Hostname string `yaml:"hostname,omitempty"`
+	Port     int    `yaml:"port,omitempty"`
 }
@@ -0,0 +1,31 @@
+package config
+
+import "time"
+
+// Startup represents how the application reports itself whilst starting, allowing orchestrators to wait until it is ready
+type Startup struct {
+	WaitForActions bool `yaml:"waitForActions,omitempty"` // not ready until every startup action has finished
+	Warmup         int  `yaml:"warmup,omitempty"`         // seconds after starting before ready
+	Unavailable    int  `yaml:"unavailable,omitempty"`    // seconds after starting every endpoint returns a 503, simulating a slow start
+}
+
+// WarmupPeriod returns the time after starting before the application is ready, which is zero for a nil Startup
+func (s *Startup) WarmupPeriod() time.Duration {
+	if s == nil {
+		return 0
+	}
+	return time.Duration(s.Warmup) * time.Second
+}
+
+// UnavailablePeriod returns the time after starting every endpoint returns a 503, which is zero for a nil Startup
+func (s *Startup) UnavailablePeriod() time.Duration {
+	if s == nil {
+		return 0
+	}
+	return time.Duration(s.Unavailable) * time.Second
+}
+
+// WaitsForActions checks if readiness waits for the startup actions to finish, which it does not for a nil Startup
+func (s *Startup) WaitsForActions() bool {
+	return s != nil && s.WaitForActions
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"sort"
 )
 
 // osHostname returns the hostname of the OS
@@ -11,14 +12,36 @@ var osHostname = os.Hostname
 // osGetenv returns the requested environment variable
 var osGetenv = os.Getenv
 
-// Validate ensures the loaded config meets the required standard
+/*Validate ensures the loaded config meets the required standard, every problem found is returned as ValidationErrors
+located by the position of its config path in the file the config was loaded from */
 func Validate(cfg *Config) (err error) {
 	switch cfg.Version {
 	case 1.0:
-		return validateV1Config(cfg)
+		err = validateV1Config(cfg)
+	case 2.0:
+		err = validateV2Config(cfg)
 	default:
-		return fmt.Errorf("Unsupported Version: %f", cfg.Version)
+		err = ValidationErrors{{Path: "version", Msg: fmt.Sprintf("Unsupported Version: %f", cfg.Version)}}
 	}
+
+	if errs, valid := err.(ValidationErrors); valid {
+		errs.locateIn(cfg)
+	}
+	return err
+}
+
+// locateIn locates each error in the files the config was loaded from, ordering them by their position
+func (e ValidationErrors) locateIn(cfg *Config) {
+	e.locate(cfg.file, cfg.positions)
+	sort.SliceStable(e, func(i, j int) bool {
+		if e[i].File != e[j].File {
+			return e[i].File < e[j].File
+		}
+		if e[i].Line != e[j].Line {
+			return e[i].Line < e[j].Line
+		}
+		return e[i].Path < e[j].Path
+	})
 }
 
 // supportedType checks if the given type string is supported
@@ -41,18 +64,6 @@ func supportedType(toCheck string) bool {
 	}
 }
 
-// supportedAction ensures a given action string is supported by the application
-func supportedAction(toCheck string) bool {
-	switch {
-	case toCheck == "delay":
-		return true
-	case toCheck == "request":
-		return true
-	default:
-		return false
-	}
-}
-
 // validateJSON ensures the given input JSON is valid (all keys are string)
 func validateJSON(input interface{}) interface{} {
 	if json, valid := input.(map[string]interface{}); valid {
@@ -73,26 +84,3 @@ func validateJSON(input interface{}) interface{} {
 	}
 	return input
 }
-
-// getEnvValueForField checks if a given string value is actually trying to use an environment variable, and replaces it if so
-func getEnvValueForField(field string) (string, error) {
-	if len(field) > 0 && string(field[0]) == "$" {
-		searchVar := field[1:]
-
-		switch {
-		case searchVar == "HOSTNAME":
-			newField, err := osHostname()
-			if err != nil {
-				return "", fmt.Errorf("Unable To Get Requested Hostname: %s", err.Error())
-			}
-			return newField, nil
-		default:
-			if result := osGetenv(searchVar); len(result) != 0 {
-				return result, nil
-			}
-			return "", fmt.Errorf("Env Var Not Found: %s", searchVar)
-		}
-	}
-
-	return field, nil
-}
@@ -2,103 +2,117 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
 )
 
-// validateV1Config validates an incoming config against version 1
+// validateV1Config validates an incoming config against version 1, collecting every error found
 func validateV1Config(cfg *Config) error {
-	serviceNames := make(map[string]bool)
-	if len(cfg.Services) > 0 {
-		for serviceName, entry := range cfg.Services {
-			if len(entry.Hostname) > 0 {
-				var err error
-				entry.Hostname, err = getEnvValueForField(entry.Hostname)
-				if err != nil {
-					return err
-				}
-			}
+	errs := new(errorList)
 
-			if len(entry.Hostname) == 0 || (entry.Port <= 0 || entry.Port > 65535) {
-				return fmt.Errorf("Invalid Service Entry For Service: %s", serviceName)
-			}
+	serviceNames := make(map[string]bool)
+	for serviceName, entry := range cfg.Services {
+		path := joinPath("services", serviceName)
+		if entry == nil {
+			errs.add(path, "Invalid Service Entry For Service: %s", serviceName)
+			continue
+		}
+		if len(entry.Hostname) == 0 {
+			errs.add(joinPath(path, "hostname"), "Invalid Service Entry For Service: %s", serviceName)
+		} else if entry.Port <= 0 || entry.Port > 65535 {
+			errs.add(joinPath(path, "port"), "Invalid Service Entry For Service: %s", serviceName)
+		} else {
 			serviceNames[serviceName] = true
 		}
 	}
 
 	if len(cfg.StartupActions) > 0 {
-		if err := validateV1Actions(cfg.StartupActions, serviceNames, cfg.Requests); err != nil {
-			return fmt.Errorf("Failed Validating Startup Actions: %s", err.Error())
-		}
+		errs.merge("startupActions", validateV1Actions(cfg.StartupActions, serviceNames, cfg.Requests))
 	}
 
-	if len(cfg.Requests) > 0 {
-		for reqName, entry := range cfg.Requests {
-			if err := validateV1Request(reqName, entry); err != nil {
-				return err
-			}
-		}
+	for reqName, entry := range cfg.Requests {
+		errs.merge(joinPath("requests", reqName), validateV1Request(reqName, entry))
+	}
+
+	if cfg.Proxy != nil {
+		errs.merge("proxy", validateV1Proxy(cfg.Proxy))
+	}
+
+	if cfg.Admin != nil {
+		errs.merge("admin", validateV1Admin(cfg.Admin))
+	}
+
+	if cfg.Startup != nil {
+		errs.merge("startup", validateV1Startup(cfg.Startup))
+	}
+
+	for name, entry := range cfg.Listeners {
+		errs.merge(joinPath("listeners", name), validateV1Listener(name, entry, serviceNames, cfg.Requests))
 	}
+	validateV1ListenerPorts(cfg, errs)
 
 	if len(cfg.Endpoints) > 0 {
 		for url, methodMap := range cfg.Endpoints {
 			for method, entry := range methodMap {
-				if err := validateV1Endpoint(url, method, entry, serviceNames, cfg.Requests); err != nil {
-					return err
-				}
+				errs.merge(joinPath("endpoints", url, method), validateV1Endpoint(url, method, entry, serviceNames, cfg.Requests))
+			}
+			if cfg.servedByAdmin(url) {
+				errs.add(joinPath("endpoints", url), "Endpoint Is Served By The Admin API At %s", cfg.Admin.PathPrefix())
 			}
 		}
-	} else {
-		return fmt.Errorf("No Endpoints Set")
+	} else if len(cfg.Listeners) == 0 && cfg.Proxy == nil {
+		errs.add("endpoints", "No Endpoints Set")
 	}
 
-	return nil
+	return errs.result()
 }
 
 // validateV1Actions ensures an 'actions' field for a V1 config is correct
-func validateV1Actions(actions []map[string]interface{}, serviceNames map[string]bool, requests map[string]*Request) error {
-	for _, actionMap := range actions {
-		for actionName, actionEntry := range actionMap {
-			if !supportedAction(actionName) {
-				return fmt.Errorf("Action Not Supported: %s", actionName)
+func validateV1Actions(actions []*Action, serviceNames map[string]bool, requests map[string]*Request) error {
+	errs := new(errorList)
+
+	for i, action := range actions {
+		if action == nil || action.count() == 0 {
+			errs.add(joinPath(i), "Action Not Supported")
+			continue
+		}
+
+		if action.Request != nil {
+			path := joinPath(i, "request")
+			if len(action.Request.Target) == 0 {
+				errs.add(path, "No Target Defined For Request Action")
+			} else if _, found := serviceNames[action.Request.Target]; !found {
+				errs.add(joinPath(path, "target"), "Service Not Defined For Request Action: %s", action.Request.Target)
 			}
-			if actionName == "request" {
-				if newActionEntry, valid := actionEntry.(map[interface{}]interface{}); valid {
-					if target, found := newActionEntry["target"]; found {
-						if _, found = serviceNames[target.(string)]; !found {
-							return fmt.Errorf("Service Not Defined For Request Action: %s", target)
-						}
-					} else {
-						return fmt.Errorf("No Target Defined For Request Action")
-					}
-
-					if requestName, found := newActionEntry["id"]; found {
-						if _, found := requests[requestName.(string)]; !found {
-							return fmt.Errorf("Request ID Not Defined For Request Action %s", requestName)
-						}
-					} else {
-						return fmt.Errorf("No Request ID Defined For Request Action")
-					}
-				} else {
-					return fmt.Errorf("Invalid Request Value For Request Action")
-				}
-			} else if actionName == "delay" {
-				if _, valid := actionEntry.(int); !valid {
-					return fmt.Errorf("Invalid Delay Value For Request Action")
-				}
+
+			if len(action.Request.ID) == 0 {
+				errs.add(path, "No Request ID Defined For Request Action")
+			} else if _, found := requests[action.Request.ID]; !found {
+				errs.add(joinPath(path, "id"), "Request ID Not Defined For Request Action: %s", action.Request.ID)
 			}
 		}
+
+		if action.Delay != nil && action.Delay.Seconds < 0 {
+			errs.add(joinPath(i, "delay"), "Invalid Delay Value For Action: %d", action.Delay.Seconds)
+		}
 	}
 
-	return nil
+	return errs.result()
 }
 
 // validateV1Parameters ensures the given parameter set is valid according to v1 schema
 func validateV1Parameters(params map[string]*ParamEntry) error {
+	errs := new(errorList)
 	for field, paramEntry := range params {
-		if !supportedType(paramEntry.Type) {
-			return fmt.Errorf("Field %s Type Is Not Supported: %s", field, paramEntry.Type)
+		if paramEntry == nil {
+			errs.add(joinPath("", field), "Field %s Is Empty", field)
+		} else if !supportedType(paramEntry.Type) {
+			errs.add(joinPath("", field, "type"), "Field %s Type Is Not Supported: %s", field, paramEntry.Type)
 		}
 	}
-	return nil
+	return errs.result()
 }
 
 // validateV1Method checks if the given http method is valid
@@ -131,77 +145,128 @@ func validateV1Protocol(protocol string) bool {
 
 // validateV1Endpoints ensures a given endpoint definition is valid
 func validateV1Endpoint(url, method string, entry *Endpoint, serviceNames map[string]bool, requests map[string]*Request) error {
+	errs := new(errorList)
+	if entry == nil {
+		errs.add("", "Endpoint For URL %s, Method %s Is Empty", url, method)
+		return errs.result()
+	}
+
 	if entry.Params != nil {
-		if entry.Params.Path != nil && len(entry.Params.Path) > 0 {
-			if err := validateV1Parameters(entry.Params.Path); err != nil {
-				return fmt.Errorf("Path Param For URL %s, Method %s Not Valid: %s", url, method, err.Error())
-			}
-		}
-		if entry.Params.Query != nil && len(entry.Params.Query) > 0 {
-			if err := validateV1Parameters(entry.Params.Query); err != nil {
-				return fmt.Errorf("Query Param For URL %s, Method %s Not Valid: %s", url, method, err.Error())
-			}
-		}
+		errs.merge("params.path", validateV1Parameters(entry.Params.Path))
+		errs.merge("params.query", validateV1Parameters(entry.Params.Query))
 	}
 
 	if entry.Recieves != nil {
 		for name, exType := range entry.Recieves.Body {
 			if !supportedType(exType) {
-				return fmt.Errorf("Body Field Type For URL %s, Method %s, Field %s Type Is Not Supported: %s", url, method, name, exType)
+				errs.add(joinPath("recieves.body", name), "Body Field Type Is Not Supported: %s", exType)
 			}
 		}
+		for path, exType := range entry.Recieves.XPath {
+			if len(path) == 0 {
+				errs.add("recieves.xpath", "Empty XPath")
+			} else if !supportedType(exType) || exType == "array" || exType == "object" {
+				errs.add(joinPath("recieves.xpath", path), "XPath Type Is Not Supported: %s", exType)
+			}
+		}
+		if len(entry.Recieves.Body) > 0 && len(entry.Recieves.XPath) > 0 {
+			errs.add("recieves", "Recieves Sets Both body And xpath")
+		}
 	}
 
-	if entry.Responses == nil && entry.Response == 0 {
-		return fmt.Errorf("Response Not Set For URL %s, Method %s", url, method)
+	if len(entry.SOAP) > 0 {
+		for operation, opEntry := range entry.SOAP {
+			path := joinPath("soap", operation)
+			if opEntry == nil {
+				errs.add(path, "SOAP Operation %s Is Empty", operation)
+			} else if len(opEntry.SOAP) > 0 {
+				errs.add(joinPath(path, "soap"), "SOAP Operation %s Cannot Define Nested SOAP Operations", operation)
+			} else {
+				errs.merge(path, validateV1Endpoint(url, fmt.Sprintf("%s (SOAP %s)", method, operation), opEntry, serviceNames, requests))
+			}
+		}
+	} else if entry.Responses == nil && entry.Response == 0 {
+		errs.add("", "Response Not Set For URL %s, Method %s", url, method)
 	}
 
 	if entry.Responses != nil {
 		totalWeight := 0
-		for _, respEntry := range entry.Responses {
+		for statusCode, respEntry := range entry.Responses {
+			path := joinPath("responses", statusCode)
+			if respEntry == nil {
+				errs.add(path, "Response %d Is Empty", statusCode)
+				continue
+			}
 			totalWeight += respEntry.Weight
 
+			errs.merge(path, validateV1ResponseBody(respEntry))
 			if len(respEntry.Actions) > 0 {
-				if err := validateV1Actions(respEntry.Actions, serviceNames, requests); err != nil {
-					return fmt.Errorf("Error Validating Response Action URL %s, Method %s: %s", url, method, err.Error())
-				}
+				errs.merge(joinPath(path, "actions"), validateV1Actions(respEntry.Actions, serviceNames, requests))
 			}
 		}
 		if totalWeight != 100 {
-			return fmt.Errorf("Response Weighting For URL %s, Method %s, Does Not Equal 100", url, method)
+			errs.add("responses", "Response Weighting Does Not Equal 100: %d", totalWeight)
 		}
 	}
 
-	if entry.Actions != nil && len(entry.Actions) > 0 {
-		if err := validateV1Actions(entry.Actions, serviceNames, requests); err != nil {
-			return fmt.Errorf("Error Validating URL %s, Method %s: %s", url, method, err.Error())
+	if len(entry.Actions) > 0 {
+		errs.merge("actions", validateV1Actions(entry.Actions, serviceNames, requests))
+	}
+
+	return errs.result()
+}
+
+// validateV1ResponseBody ensures at most one body source is set for a response
+func validateV1ResponseBody(entry *Response) error {
+	errs := new(errorList)
+
+	sources := 0
+	if len(entry.Body) > 0 {
+		sources++
+	}
+	if len(entry.RawBody) > 0 {
+		sources++
+	}
+	if len(entry.BodyFile) > 0 {
+		sources++
+	}
+	if entry.Fault != nil {
+		if len(entry.Fault.Code) == 0 || len(entry.Fault.String) == 0 {
+			errs.add("fault", "SOAP Fault Requires Both code And string")
 		}
+		sources++
 	}
 
-	return nil
+	if sources > 1 {
+		errs.add("", "Only One Of body, rawBody, bodyFile Or fault May Be Set")
+	}
+	return errs.result()
 }
 
 // validateV1Request ensures a given request field is valid; only mandatory fields are URL and expected response code
 func validateV1Request(reqName string, entry *Request) error {
+	errs := new(errorList)
+
 	if entry == nil {
-		return fmt.Errorf("Entry Is Nil")
+		errs.add("", "Entry Is Nil")
+		return errs.result()
 	}
 
 	if len(reqName) == 0 {
-		return fmt.Errorf("reqName Is Empty")
+		errs.add("", "reqName Is Empty")
 	}
 
 	if len(entry.URL) == 0 {
-		return fmt.Errorf("URL For Request %s Is Empty", reqName)
+		errs.add("url", "URL For Request %s Is Empty", reqName)
 	}
 
 	if !validateV1Method(entry.Method) {
-		return fmt.Errorf("Method %s For Request %s Not Supported", entry.Method, reqName)
+		errs.add("method", "Method %s For Request %s Not Supported", entry.Method, reqName)
 	}
 
 	if len(entry.Protocol) > 0 {
 		if !validateV1Protocol(entry.Protocol) {
-			return fmt.Errorf("Protocol %s For Request %s Not Supported", entry.Protocol, reqName)
+			errs.add("protocol", "Protocol %s For Request %s Not Supported", entry.Protocol, reqName)
 		}
 	} else {
 		entry.Protocol = "http" // default if ommitted
@@ -209,14 +274,12 @@ func validateV1Request(reqName string, entry *Request) error {
 
 	if entry.ExpectedResponse != nil {
 		if entry.ExpectedResponse.StatusCode == 0 {
-			return fmt.Errorf("Status Code For Request %s Is Invalid", reqName)
+			errs.add("expectedResponse.statusCode", "Status Code For Request %s Is Invalid", reqName)
 		}
 
-		if entry.ExpectedResponse.Body != nil && len(entry.ExpectedResponse.Body) > 0 {
-			for expectedField, expectedType := range entry.ExpectedResponse.Body {
-				if strExpectedType, ok := expectedType.(string); ok && !supportedType(strExpectedType) {
-					return fmt.Errorf("Request %s Expected Response Invalid Expected Type For Field %s: %s", reqName, expectedField, expectedType)
-				}
+		for expectedField, expectedType := range entry.ExpectedResponse.Body {
+			if strExpectedType, ok := expectedType.(string); ok && !supportedType(strExpectedType) {
+				errs.add(joinPath("expectedResponse.body", expectedField), "Invalid Expected Type: %s", expectedType)
 			}
 		}
 	}
@@ -225,5 +288,169 @@ func validateV1Request(reqName string, entry *Request) error {
 		entry.Body = validateJSON(entry.Body).(map[string]interface{})
 	}
 
+	return errs.result()
+}
+
+// validateV1Proxy ensures the proxy targets are valid upstream URLs
+func validateV1Proxy(entry *Proxy) error {
+	errs := new(errorList)
+
+	if len(entry.Target) == 0 && len(entry.Routes) == 0 {
+		errs.add("", "Proxy Requires A target Or routes")
+	}
+
+	if len(entry.Target) > 0 {
+		if err := validateV1Upstream(entry.Target); err != nil {
+			errs.add("target", "Invalid Proxy Target: %s", err.Error())
+		}
+	}
+
+	for prefix, target := range entry.Routes {
+		path := joinPath("routes", prefix)
+		if !strings.HasPrefix(prefix, "/") {
+			errs.add(path, "Proxy Route Prefix %s Must Begin With /", prefix)
+		}
+		if err := validateV1Upstream(target); err != nil {
+			errs.add(path, "Invalid Proxy Target For Route %s: %s", prefix, err.Error())
+		}
+	}
+
+	return errs.result()
+}
+
+// validateV1Admin ensures the admin prefix begins with / and is not /, and the port and journal size are in range
+func validateV1Admin(entry *Admin) error {
+	errs := new(errorList)
+
+	if len(entry.Prefix) > 0 && (!strings.HasPrefix(entry.Prefix, "/") || entry.PathPrefix() == "") {
+		errs.add("prefix", "Admin Prefix %s Must Begin With / And Not Be /", entry.Prefix)
+	}
+	if entry.Port < 0 || entry.Port > 65535 {
+		errs.add("port", "Invalid Admin Port: %d", entry.Port)
+	}
+	if entry.Journal < 0 {
+		errs.add("journal", "Invalid Admin Journal Size: %d", entry.Journal)
+	}
+	return errs.result()
+}
+
+// validateV1Startup ensures the startup periods are not negative
+func validateV1Startup(entry *Startup) error {
+	errs := new(errorList)
+
+	if entry.Warmup < 0 {
+		errs.add("warmup", "Invalid Startup Warmup: %d", entry.Warmup)
+	}
+	if entry.Unavailable < 0 {
+		errs.add("unavailable", "Invalid Startup Unavailable Period: %d", entry.Unavailable)
+	}
+
+	return errs.result()
+}
+
+// validateV1Upstream ensures the given string is an absolute http(s) URL
+func validateV1Upstream(target string) error {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+	if !validateV1Protocol(parsed.Scheme) || len(parsed.Host) == 0 {
+		return fmt.Errorf("%s Is Not An Absolute http Or https URL", target)
+	}
 	return nil
 }
+
+// validateV1ListenerPorts ensures no two listeners share a protocol and port, and no TCP listener uses the admin port
+func validateV1ListenerPorts(cfg *Config, errs *errorList) {
+	names := make([]string, 0, len(cfg.Listeners))
+	for name := range cfg.Listeners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	used := make(map[string]string) // protocol:port -> listener name
+	for _, name := range names {
+		entry := cfg.Listeners[name]
+		if entry == nil || entry.Port <= 0 {
+			continue
+		}
+		path := joinPath("listeners", name, "port")
+
+		key := fmt.Sprintf("%s:%d", entry.Protocol, entry.Port)
+		if other, found := used[key]; found {
+			errs.add(path, "Port %d Is Already Used By Listener %s", entry.Port, other)
+		} else {
+			used[key] = name
+		}
+		if entry.Protocol == "tcp" && cfg.Admin != nil && entry.Port == cfg.Admin.Port {
+			errs.add(path, "Port %d Is Already Used By The Admin API", entry.Port)
+		}
+	}
+}
+
+// validateV1Listener ensures a given raw TCP/UDP listener definition is valid
+func validateV1Listener(name string, entry *Listener, serviceNames map[string]bool, requests map[string]*Request) error {
+	errs := new(errorList)
+
+	if entry == nil {
+		errs.add("", "Listener %s Is Empty", name)
+		return errs.result()
+	}
+
+	if entry.Protocol != "tcp" && entry.Protocol != "udp" {
+		errs.add("protocol", "Protocol %s For Listener %s Not Supported", entry.Protocol, name)
+	}
+
+	if entry.Port <= 0 || entry.Port > 65535 {
+		errs.add("port", "Invalid Port For Listener %s", name)
+	}
+
+	if len(entry.Mode) > 0 {
+		if entry.Mode != "line" && entry.Mode != "raw" {
+			errs.add("mode", "Mode %s For Listener %s Not Supported", entry.Mode, name)
+		}
+	} else {
+		entry.Mode = "line" // default if ommitted
+	}
+
+	if len(entry.Rules) == 0 {
+		errs.add("rules", "No Rules Set For Listener %s", name)
+	}
+
+	for i, rule := range entry.Rules {
+		path := joinPath("rules", i)
+		if rule == nil {
+			errs.add(path, "Rule %d For Listener %s Is Empty", i, name)
+			continue
+		}
+		if len(rule.Match) > 0 && len(rule.MatchHex) > 0 {
+			errs.add(path, "Rule Sets Both match And matchHex")
+		}
+		if len(rule.Match) > 0 {
+			if _, err := regexp.Compile(rule.Match); err != nil {
+				errs.add(joinPath(path, "match"), "Invalid Regex: %s", err.Error())
+			}
+		}
+		if len(rule.MatchHex) > 0 {
+			if _, err := ParseHexPattern(rule.MatchHex); err != nil {
+				errs.add(joinPath(path, "matchHex"), "%s", err.Error())
+			}
+		}
+		if len(rule.Reply) > 0 && len(rule.ReplyHex) > 0 {
+			errs.add(path, "Rule Sets Both reply And replyHex")
+		}
+		if len(rule.ReplyHex) > 0 {
+			if _, err := DecodeHex(rule.ReplyHex); err != nil {
+				errs.add(joinPath(path, "replyHex"), "Invalid replyHex: %s", err.Error())
+			}
+		}
+		if rule.DelayMs < 0 {
+			errs.add(joinPath(path, "delayMs"), "Negative delayMs")
+		}
+		if len(rule.Actions) > 0 {
+			errs.merge(joinPath(path, "actions"), validateV1Actions(rule.Actions, serviceNames, requests))
+		}
+	}
+
+	return errs.result()
+}
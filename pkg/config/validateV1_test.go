@@ -1,6 +1,11 @@
 package config
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
 
 // TestValidateV1Config has no endpoints set on the incoming config, should fail
 func TestValidateV1Config1(t *testing.T) {
@@ -9,9 +14,9 @@ func TestValidateV1Config1(t *testing.T) {
 		Services: map[string]*Service{
 			"testService": {Hostname: "localhost", Port: 8080},
 		},
-		StartupActions: []map[string]interface{}{
-			{"delay": 10},
-			{"request": map[interface{}]interface{}{"target": "testService", "id": "testRequest"}},
+		StartupActions: []*Action{
+			{Delay: &DelayAction{Seconds: 10}},
+			{Request: &RequestAction{Target: "testService", ID: "testRequest"}},
 		},
 		Requests: map[string]*Request{
 			"testRequest": {
@@ -25,9 +30,9 @@ func TestValidateV1Config1(t *testing.T) {
 					Body:       map[string]interface{}{"foo.bar": "string"},
 					Headers:    nil,
 					Weight:     100,
-					Actions: []map[string]interface{}{
-						{"delay": 10},
-						{"request": map[interface{}]interface{}{"target": "testService", "id": "testRequest"}},
+					Actions: []*Action{
+						{Delay: &DelayAction{Seconds: 10}},
+						{Request: &RequestAction{Target: "testService", ID: "testRequest"}},
 					},
 				},
 			},
@@ -51,9 +56,9 @@ func TestValidateV1Config2(t *testing.T) {
 		Services: map[string]*Service{
 			"testService": {Hostname: "localhost", Port: 8080},
 		},
-		StartupActions: []map[string]interface{}{
-			{"delay": 10},
-			{"request": map[interface{}]interface{}{"target": "testService", "id": "testRequest"}},
+		StartupActions: []*Action{
+			{Delay: &DelayAction{Seconds: 10}},
+			{Request: &RequestAction{Target: "testService", ID: "testRequest"}},
 		},
 		Requests: map[string]*Request{
 			"testRequest": {
@@ -67,9 +72,9 @@ func TestValidateV1Config2(t *testing.T) {
 					Body:       map[string]interface{}{"foo.bar": "string"},
 					Headers:    nil,
 					Weight:     100,
-					Actions: []map[string]interface{}{
-						{"delay": 10},
-						{"request": map[interface{}]interface{}{"target": "testService", "id": "testRequest"}},
+					Actions: []*Action{
+						{Delay: &DelayAction{Seconds: 10}},
+						{Request: &RequestAction{Target: "testService", ID: "testRequest"}},
 					},
 				},
 			},
@@ -89,9 +94,9 @@ func TestValidateV1Config3(t *testing.T) {
 		Services: map[string]*Service{
 			"testService": {Hostname: "localhost", Port: 8080},
 		},
-		StartupActions: []map[string]interface{}{
-			{"delay": 10},
-			{"request": map[interface{}]interface{}{"target": "testService", "id": "testRequest"}},
+		StartupActions: []*Action{
+			{Delay: &DelayAction{Seconds: 10}},
+			{Request: &RequestAction{Target: "testService", ID: "testRequest"}},
 		},
 		Requests: map[string]*Request{
 			"testRequest": nil,
@@ -111,7 +116,7 @@ func TestValidateV1Config4(t *testing.T) {
 		Services: map[string]*Service{
 			"testService": {Hostname: "localhost", Port: 8080},
 		},
-		StartupActions: []map[string]interface{}{{"delay": nil}},
+		StartupActions: []*Action{{}},
 	}
 
 	if err := Validate(cfg); err == nil {
@@ -133,27 +138,11 @@ func TestValidateV1Config5(t *testing.T) {
 	}
 }
 
-// TestValidateV1Config6 attempts to get the hostname of the OS but experiences an error getting it
-func TestValidateV1Config6(t *testing.T) {
-	osHostname = mockInvalidOsHostname
-
-	cfg := &Config{
-		Version: 1.0,
-		Services: map[string]*Service{
-			"testService": {Hostname: "$HOSTNAME", Port: 8080},
-		},
-	}
-
-	if err := Validate(cfg); err == nil {
-		t.Errorf("Validation Failed, No Error Raised")
-	}
-}
-
 // TestValidateV1Actions1 ensures a correct set of actions does not raise any errors
 func TestValidateV1Actions1(t *testing.T) {
-	actions := []map[string]interface{}{
-		{"delay": 10},
-		{"request": map[interface{}]interface{}{"target": "testService", "id": "testRequest"}},
+	actions := []*Action{
+		{Delay: &DelayAction{Seconds: 10}},
+		{Request: &RequestAction{Target: "testService", ID: "testRequest"}},
 	}
 	requests := map[string]*Request{"testRequest": nil}
 
@@ -164,9 +153,9 @@ func TestValidateV1Actions1(t *testing.T) {
 
 // TestValidateV1Actions2 ensures when a missing request is referenced, it is raised to the user
 func TestValidateV1Actions2(t *testing.T) {
-	actions := []map[string]interface{}{
-		{"delay": 10},
-		{"request": map[interface{}]interface{}{"target": "testService", "id": "testRequest"}},
+	actions := []*Action{
+		{Delay: &DelayAction{Seconds: 10}},
+		{Request: &RequestAction{Target: "testService", ID: "testRequest"}},
 	}
 
 	if err := validateV1Actions(actions, map[string]bool{"testService": true}, make(map[string]*Request)); err == nil {
@@ -176,9 +165,9 @@ func TestValidateV1Actions2(t *testing.T) {
 
 // TestValidateV1Actions3 ensures when a requestID is missing from a 'request' action, it is raised to the user
 func TestValidateV1Actions3(t *testing.T) {
-	actions := []map[string]interface{}{
-		{"delay": 10},
-		{"request": map[interface{}]interface{}{"target": "testService"}},
+	actions := []*Action{
+		{Delay: &DelayAction{Seconds: 10}},
+		{Request: &RequestAction{Target: "testService"}},
 	}
 
 	if err := validateV1Actions(actions, map[string]bool{"testService": true}, make(map[string]*Request)); err == nil {
@@ -188,9 +177,9 @@ func TestValidateV1Actions3(t *testing.T) {
 
 // TestValidateV1Actions4 ensures when a request uses a target service that is not defined, it is raised to the user
 func TestValidateV1Actions4(t *testing.T) {
-	actions := []map[string]interface{}{
-		{"delay": 10},
-		{"request": map[interface{}]interface{}{"target": "testService"}},
+	actions := []*Action{
+		{Delay: &DelayAction{Seconds: 10}},
+		{Request: &RequestAction{Target: "testService"}},
 	}
 
 	if err := validateV1Actions(actions, make(map[string]bool), make(map[string]*Request)); err == nil {
@@ -200,9 +189,9 @@ func TestValidateV1Actions4(t *testing.T) {
 
 // TestValidateV1Actions5 ensures when a request is missing a target service, it is raised to the user
 func TestValidateV1Actions5(t *testing.T) {
-	actions := []map[string]interface{}{
-		{"delay": 10},
-		{"request": make(map[interface{}]interface{})},
+	actions := []*Action{
+		{Delay: &DelayAction{Seconds: 10}},
+		{Request: &RequestAction{}},
 	}
 
 	if err := validateV1Actions(actions, make(map[string]bool), make(map[string]*Request)); err == nil {
@@ -210,22 +199,20 @@ func TestValidateV1Actions5(t *testing.T) {
 	}
 }
 
-// TestValidateV1Actions6 ensures when a request is invalid formatted, it is raised to the user
+// TestValidateV1Actions6 ensures when a request is invalid formatted, it is raised to the user when decoded
 func TestValidateV1Actions6(t *testing.T) {
-	actions := []map[string]interface{}{
-		{"delay": 10},
-		{"request": "invalid"},
-	}
+	actions := make([]*Action, 0)
 
-	if err := validateV1Actions(actions, make(map[string]bool), make(map[string]*Request)); err == nil {
-		t.Errorf("validateV1Actions No Error Raised")
+	if err := yaml.Unmarshal([]byte("- delay: 10\n- request: invalid\n"), &actions); err == nil {
+		t.Errorf("Invalid Request Action Decoded With No Error")
 	}
 }
 
 // TestValidateV1Actions7 ensures when an invalid action is requested, it is raised to the user
 func TestValidateV1Actions7(t *testing.T) {
-	actions := []map[string]interface{}{
-		{"unsupported": "foo"},
+	actions := make([]*Action, 0)
+	if err := yaml.Unmarshal([]byte("- unsupported: foo\n"), &actions); err != nil {
+		t.Fatalf("Error Decoding Actions: %s", err.Error())
 	}
 
 	if err := validateV1Actions(actions, make(map[string]bool), make(map[string]*Request)); err == nil {
@@ -233,6 +220,21 @@ func TestValidateV1Actions7(t *testing.T) {
 	}
 }
 
+// TestValidateV1Actions8 ensures the v1 delay form of a number of seconds decodes, and a string is rejected
+func TestValidateV1Actions8(t *testing.T) {
+	actions := make([]*Action, 0)
+	if err := yaml.Unmarshal([]byte("- delay: 5\n- delay: {seconds: 2}\n"), &actions); err != nil {
+		t.Fatalf("Error Decoding Actions: %s", err.Error())
+	}
+	if actions[0].Delay.Seconds != 5 || actions[1].Delay.Seconds != 2 {
+		t.Errorf("Incorrect Delays Decoded: %d, %d", actions[0].Delay.Seconds, actions[1].Delay.Seconds)
+	}
+
+	if err := yaml.Unmarshal([]byte("- delay: \"5\"\n"), &actions); err == nil {
+		t.Errorf("String Delay Decoded With No Error")
+	}
+}
+
 // TestValidateV1Parameters1 ensures a given set of correctly formatted parameters is not raised as an error
 func TestValidateV1Parameters1(t *testing.T) {
 	params := map[string]*ParamEntry{
@@ -304,13 +306,13 @@ func TestValidateV1Endpoint1(t *testing.T) {
 				Body:       map[string]interface{}{"foo.bar": "string"},
 				Headers:    nil,
 				Weight:     100,
-				Actions: []map[string]interface{}{
-					{"delay": 10},
+				Actions: []*Action{
+					{Delay: &DelayAction{Seconds: 10}},
 				},
 			},
 		},
-		Actions: []map[string]interface{}{
-			{"delay": 10},
+		Actions: []*Action{
+			{Delay: &DelayAction{Seconds: 10}},
 		},
 	}
 
@@ -336,13 +338,13 @@ func TestValidateV1Endpoint2(t *testing.T) {
 				Body:       map[string]interface{}{"foo.bar": "string"},
 				Headers:    nil,
 				Weight:     100,
-				Actions: []map[string]interface{}{
-					{"delay": 10},
+				Actions: []*Action{
+					{Delay: &DelayAction{Seconds: 10}},
 				},
 			},
 		},
-		Actions: []map[string]interface{}{
-			{"delay": "foobar"},
+		Actions: []*Action{
+			{Delay: &DelayAction{Seconds: -1}},
 		},
 	}
 
@@ -368,8 +370,8 @@ func TestValidateV1Endpoint3(t *testing.T) {
 				Body:       map[string]interface{}{"foo.bar": "string"},
 				Headers:    nil,
 				Weight:     100,
-				Actions: []map[string]interface{}{
-					{"delay": "foobar"},
+				Actions: []*Action{
+					{Delay: &DelayAction{Seconds: -1}},
 				},
 			},
 		},
@@ -593,3 +595,184 @@ func TestValidateV1Request9(t *testing.T) {
 		t.Errorf("Invalid Request Incorrectly Identified As Valid")
 	}
 }
+
+// TestValidateV1Listener1 ensures a valid listener passes validation and has its mode defaulted
+func TestValidateV1Listener1(t *testing.T) {
+	entry := &Listener{
+		Protocol: "tcp",
+		Port:     7000,
+		Rules: []*ListenerRule{
+			{Match: "^PING", Reply: "PONG"},
+			{MatchHex: "de ad ?? ef", ReplyHex: "00 01", Close: true},
+			{Reply: "ERR"},
+		},
+	}
+
+	if err := validateV1Listener("test", entry, map[string]bool{}, map[string]*Request{}); err != nil {
+		t.Errorf("Validation Failed: %s", err.Error())
+	}
+
+	if entry.Mode != "line" {
+		t.Errorf("Mode Not Defaulted To line: %s", entry.Mode)
+	}
+}
+
+// TestValidateV1Listener2 ensures invalid listener definitions are returned as errors
+func TestValidateV1Listener2(t *testing.T) {
+	for _, entry := range []*Listener{
+		{Protocol: "sctp", Port: 7000, Rules: []*ListenerRule{{Reply: "OK"}}},
+		{Protocol: "udp", Port: 0, Rules: []*ListenerRule{{Reply: "OK"}}},
+		{Protocol: "udp", Port: 7000, Mode: "binary", Rules: []*ListenerRule{{Reply: "OK"}}},
+		{Protocol: "udp", Port: 7000},
+		{Protocol: "tcp", Port: 7000, Rules: []*ListenerRule{{Match: "(unclosed"}}},
+		{Protocol: "tcp", Port: 7000, Rules: []*ListenerRule{{MatchHex: "abc"}}},
+		{Protocol: "tcp", Port: 7000, Rules: []*ListenerRule{{Match: "a", MatchHex: "ab"}}},
+		{Protocol: "tcp", Port: 7000, Rules: []*ListenerRule{{Reply: "a", ReplyHex: "ab"}}},
+		{Protocol: "tcp", Port: 7000, Rules: []*ListenerRule{{Actions: []*Action{{}}}}},
+	} {
+		if err := validateV1Listener("test", entry, map[string]bool{}, map[string]*Request{}); err == nil {
+			t.Errorf("Invalid Listener Returned No Error: %+v", entry)
+		}
+	}
+}
+
+// TestValidateV1Listener3 ensures listeners sharing a protocol and port, or using the admin port, are returned as errors
+func TestValidateV1Listener3(t *testing.T) {
+	rules := []*ListenerRule{{Reply: "OK"}}
+	cfg := &Config{
+		Version: 1.0,
+		Listeners: map[string]*Listener{
+			"a": {Protocol: "tcp", Port: 7000, Rules: rules},
+			"b": {Protocol: "udp", Port: 7000, Rules: rules},
+		},
+	}
+	if err := validateV1Config(cfg); err != nil {
+		t.Errorf("TCP And UDP Listeners On One Port Returned Error: %s", err.Error())
+	}
+
+	cfg.Listeners["c"] = &Listener{Protocol: "tcp", Port: 7000, Rules: rules}
+	if err := validateV1Config(cfg); err == nil || !strings.Contains(err.Error(), "Already Used By Listener a") {
+		t.Errorf("Listeners Sharing A Port Returned Unexpected Error: %v", err)
+	}
+
+	delete(cfg.Listeners, "c")
+	cfg.Admin = &Admin{Port: 7000}
+	if err := validateV1Config(cfg); err == nil || !strings.Contains(err.Error(), "Admin API") {
+		t.Errorf("Listener On The Admin Port Returned Unexpected Error: %v", err)
+	}
+}
+
+// TestValidateV1Endpoint9 ensures a SOAP endpoint is valid without its own response, and validates each operation
+func TestValidateV1Endpoint9(t *testing.T) {
+	entry := &Endpoint{
+		SOAP: map[string]*Endpoint{
+			"urn:Authorise": {
+				Recieves: &Recieves{XPath: map[string]string{"//Amount": "float"}},
+				Responses: map[int]*Response{
+					200: {RawBody: "<ok/>", Weight: 50},
+					500: {Fault: &Fault{Code: "soap:Server", String: "Declined"}, Weight: 50},
+				},
+			},
+		},
+	}
+
+	if err := validateV1Endpoint("/soap", "post", entry, map[string]bool{}, map[string]*Request{}); err != nil {
+		t.Errorf("Validation Failed: %s", err.Error())
+	}
+}
+
+// TestValidateV1Endpoint10 ensures invalid SOAP operations and response bodies are returned as errors
+func TestValidateV1Endpoint10(t *testing.T) {
+	for _, entry := range []*Endpoint{
+		{SOAP: map[string]*Endpoint{"op": {}}},
+		{SOAP: map[string]*Endpoint{"op": {Response: 200, SOAP: map[string]*Endpoint{"nested": {Response: 200}}}}},
+		{SOAP: map[string]*Endpoint{"op": {Response: 200, Recieves: &Recieves{XPath: map[string]string{"//a": "object"}}}}},
+		{Responses: map[int]*Response{200: {Weight: 100, RawBody: "a", BodyFile: "b"}}},
+		{Responses: map[int]*Response{500: {Weight: 100, Fault: &Fault{Code: "soap:Server"}}}},
+	} {
+		if err := validateV1Endpoint("/soap", "post", entry, map[string]bool{}, map[string]*Request{}); err == nil {
+			t.Errorf("Invalid Endpoint Returned No Error: %+v", entry)
+		}
+	}
+}
+
+// TestValidateV1Proxy1 ensures a proxy with a global target and routes is valid
+func TestValidateV1Proxy1(t *testing.T) {
+	entry := &Proxy{
+		Target: "http://localhost:9000",
+		Routes: map[string]string{"/api/v2/": "https://example.com:8443"},
+	}
+
+	if err := validateV1Proxy(entry); err != nil {
+		t.Errorf("Validation Failed: %s", err.Error())
+	}
+}
+
+// TestValidateV1Proxy2 ensures invalid proxy definitions are returned as errors
+func TestValidateV1Proxy2(t *testing.T) {
+	for _, entry := range []*Proxy{
+		{},
+		{Target: "localhost:9000"},
+		{Target: "ftp://localhost:9000"},
+		{Routes: map[string]string{"api/": "http://localhost:9000"}},
+		{Routes: map[string]string{"/api/": "/relative"}},
+	} {
+		if err := validateV1Proxy(entry); err == nil {
+			t.Errorf("Invalid Proxy Returned No Error: %+v", entry)
+		}
+	}
+}
+
+// TestValidateV1Admin1 ensures admin settings with a prefix, port and token are valid, leaving the token unchanged
+func TestValidateV1Admin1(t *testing.T) {
+	entry := &Admin{Prefix: "/_internal/", Port: 9090, Token: "$ADMIN_TOKEN"}
+	if err := validateV1Admin(entry); err != nil {
+		t.Errorf("Validation Failed: %s", err.Error())
+	}
+	if entry.Token != "$ADMIN_TOKEN" {
+		t.Errorf("Token Expanded During Validation: %s", entry.Token)
+	}
+	if entry.PathPrefix() != "/_internal" {
+		t.Errorf("Unexpected Prefix: %s", entry.PathPrefix())
+	}
+}
+
+// TestValidateV1Admin2 ensures invalid admin settings and endpoints served by the admin API are returned as errors
+func TestValidateV1Admin2(t *testing.T) {
+	for _, entry := range []*Admin{
+		{Prefix: "admin"},
+		{Prefix: "/"},
+		{Port: 70000},
+		{Journal: -1},
+	} {
+		if err := validateV1Admin(entry); err == nil {
+			t.Errorf("Invalid Admin Returned No Error: %+v", entry)
+		}
+	}
+
+	cfg := &Config{
+		Version:   1.0,
+		Endpoints: map[string]map[string]*Endpoint{"/__admin/v1/stats": {"get": {Response: 200}}},
+	}
+	if err := validateV1Config(cfg); err == nil {
+		t.Errorf("Endpoint Under The Admin Prefix Returned No Error")
+	}
+
+	cfg.Admin = &Admin{Port: 9090}
+	if err := validateV1Config(cfg); err != nil {
+		t.Errorf("Endpoint Under The Admin Prefix Of Another Port Returned Error: %s", err.Error())
+	}
+}
+
+// TestValidateV1Startup1 ensures negative startup periods are returned as errors
+func TestValidateV1Startup1(t *testing.T) {
+	if err := validateV1Startup(&Startup{WaitForActions: true, Warmup: 5, Unavailable: 10}); err != nil {
+		t.Errorf("Valid Startup Returned Error: %s", err.Error())
+	}
+
+	for _, entry := range []*Startup{{Warmup: -1}, {Unavailable: -1}} {
+		if err := validateV1Startup(entry); err == nil {
+			t.Errorf("Invalid Startup Returned No Error: %+v", entry)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+/*validateV2Config validates an incoming config against version 2, which shares the v1 schema but is strictly decoded
+and requires every action to set exactly one action type */
+func validateV2Config(cfg *Config) error {
+	errs := new(errorList)
+
+	validateV2ActionTypes(cfg, errs)
+
+	errs.merge("", validateV1Config(cfg))
+	return errs.result()
+}
+
+// validateV2ActionTypes ensures every action within the config sets exactly one action type
+func validateV2ActionTypes(cfg *Config, errs *errorList) {
+	for path, actions := range configActions(cfg) {
+		validateV2Actions(path, *actions, errs)
+	}
+}
+
+// validateV2Actions ensures every action within a list sets exactly one action type
+func validateV2Actions(path string, actions []*Action, errs *errorList) {
+	for i, action := range actions {
+		if action != nil && action.count() > 1 {
+			errs.add(joinPath(path, i), "Action Sets More Than One Action Type")
+		}
+	}
+}
+
+// configActions returns every list of actions within the config, keyed by its config path
+func configActions(cfg *Config) map[string]*[]*Action {
+	result := make(map[string]*[]*Action)
+	if len(cfg.StartupActions) > 0 {
+		result["startupActions"] = &cfg.StartupActions
+	}
+
+	var addEndpoint func(path string, entry *Endpoint)
+	addEndpoint = func(path string, entry *Endpoint) {
+		if entry == nil {
+			return
+		}
+		if len(entry.Actions) > 0 {
+			result[joinPath(path, "actions")] = &entry.Actions
+		}
+		for statusCode, resp := range entry.Responses {
+			if resp != nil && len(resp.Actions) > 0 {
+				result[joinPath(path, "responses", statusCode, "actions")] = &resp.Actions
+			}
+		}
+		for operation, opEntry := range entry.SOAP {
+			addEndpoint(joinPath(path, "soap", operation), opEntry)
+		}
+	}
+
+	for url, methods := range cfg.Endpoints {
+		for method, entry := range methods {
+			addEndpoint(joinPath("endpoints", url, method), entry)
+		}
+	}
+
+	for name, listener := range cfg.Listeners {
+		if listener == nil {
+			continue
+		}
+		for i, rule := range listener.Rules {
+			if rule != nil && len(rule.Actions) > 0 {
+				result[joinPath("listeners", name, "rules", i, "actions")] = &rule.Actions
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,43 @@
+package config
+
+import "testing"
+
+// TestValidateV2Config1 ensures an action setting more than one action type is raised as an error with its location
+func TestValidateV2Config1(t *testing.T) {
+	cfg := &Config{
+		Version:  2.0,
+		Services: map[string]*Service{"testService": {Hostname: "localhost", Port: 8080}},
+		Requests: map[string]*Request{"testRequest": {URL: "/test", Method: "get"}},
+		Endpoints: map[string]map[string]*Endpoint{
+			"/test": {"get": {
+				Response: 200,
+				Actions: []*Action{
+					{Delay: &DelayAction{Seconds: 1}, Request: &RequestAction{Target: "testService", ID: "testRequest"}},
+				},
+			}},
+		},
+	}
+
+	err := Validate(cfg)
+	if err == nil {
+		t.Fatalf("Validation Failed, No Error Raised")
+	}
+	if err.Error() != "endpoints./test.get.actions.0: Action Sets More Than One Action Type" {
+		t.Errorf("Incorrect Error: %s", err.Error())
+	}
+}
+
+// TestValidateV2Config2 ensures a valid v2 config passes validation
+func TestValidateV2Config2(t *testing.T) {
+	cfg := &Config{
+		Version:        2.0,
+		Services:       map[string]*Service{"testService": {Hostname: "localhost", Port: 8080}},
+		Requests:       map[string]*Request{"testRequest": {URL: "/test", Method: "get"}},
+		StartupActions: []*Action{{Delay: &DelayAction{Seconds: 1}}, {Request: &RequestAction{Target: "testService", ID: "testRequest"}}},
+		Endpoints:      map[string]map[string]*Endpoint{"/test": {"get": {Response: 200}}},
+	}
+
+	if err := Validate(cfg); err != nil {
+		t.Errorf("Validation Failed: %s", err.Error())
+	}
+}
@@ -22,15 +22,15 @@ func TestValidate1(t *testing.T) {
 					Headers: map[string]string{"foo": "bar"},
 					Body:    map[string]interface{}{"bar": "foo"},
 					Weight:  100,
-					Actions: []map[string]interface{}{
-						{"delay": 10},
-						{"request": map[interface{}]interface{}{"target": "testService", "id": "testRequest"}},
+					Actions: []*Action{
+						{Delay: &DelayAction{Seconds: 10}},
+						{Request: &RequestAction{Target: "testService", ID: "testRequest"}},
 					},
 				},
 			},
-			Actions: []map[string]interface{}{
-				{"delay": 10},
-				{"request": map[interface{}]interface{}{"target": "testService", "id": "testRequest"}},
+			Actions: []*Action{
+				{Delay: &DelayAction{Seconds: 10}},
+				{Request: &RequestAction{Target: "testService", ID: "testRequest"}},
 			},
 		},
 	}
@@ -40,9 +40,9 @@ func TestValidate1(t *testing.T) {
 		Services: map[string]*Service{
 			"testService": {Hostname: "localhost", Port: 8080},
 		},
-		StartupActions: []map[string]interface{}{
-			{"delay": 10},
-			{"request": map[interface{}]interface{}{"target": "testService", "id": "testRequest"}},
+		StartupActions: []*Action{
+			{Delay: &DelayAction{Seconds: 10}},
+			{Request: &RequestAction{Target: "testService", ID: "testRequest"}},
 		},
 		Requests: map[string]*Request{
 			"testRequest": {
@@ -56,9 +56,9 @@ func TestValidate1(t *testing.T) {
 					Body:       map[string]interface{}{"foo.bar": "string"},
 					Headers:    nil,
 					Weight:     100,
-					Actions: []map[string]interface{}{
-						{"delay": 10},
-						{"request": map[interface{}]interface{}{"target": "testService", "id": "testRequest"}},
+					Actions: []*Action{
+						{Delay: &DelayAction{Seconds: 10}},
+						{Request: &RequestAction{Target: "testService", ID: "testRequest"}},
 					},
 				},
 			},
@@ -96,22 +96,6 @@ func TestSupportedType2(t *testing.T) {
 	}
 }
 
-// TestSupportedAction1 ensures all the supported action types are returned correctly as supported
-func TestSupportedAction1(t *testing.T) {
-	for _, action := range []string{"delay", "request"} {
-		if !supportedAction(action) {
-			t.Errorf("Supported Action '%s' Returned As Unsupported", action)
-		}
-	}
-}
-
-// TestSupportedAction2 ensures an unsupported action is not returned as supported
-func TestSupportedAction2(t *testing.T) {
-	if supportedAction("unsupported") {
-		t.Errorf("Unsupported Action 'unsupported' Returned As Supported")
-	}
-}
-
 // TestValidateJSON1 ensures a given piece of JSON without string-set keys is returned with string-set keys
 func TestValidateJSON1(t *testing.T) {
 	input := map[string]interface{}{
@@ -143,40 +127,3 @@ func TestValidateJSON1(t *testing.T) {
 		t.Errorf("Returned Validated JSON Is Invalid: tmp1")
 	}
 }
-
-// TestValidGetEnvValueForField1 ensures a correct response for getting hostname, env vars and an unmodified value
-func TestValidGetEnvValueForField(t *testing.T) {
-	osHostname = mockValidOsHostname
-	osGetenv = mockValidOsGetenv
-
-	for _, dataToCheck := range []string{"$HOSTNAME", "$ENV_VAR", "test_value"} {
-		checkedData, err := getEnvValueForField(dataToCheck)
-
-		if err != nil {
-			t.Errorf("Error Encountered Getting Env Value For Field: %s", err.Error())
-		}
-
-		if checkedData != "test_value" {
-			t.Errorf("Value Does Not Match 'test_value': %s", checkedData)
-		}
-	}
-
-}
-
-// TestValidGetEnvValueForField2 ensures when a hostname cannot be found, an error is returned
-func TestValidGetEnvValueForField2(t *testing.T) {
-	osHostname = mockInvalidOsHostname
-
-	if _, err := getEnvValueForField("$HOSTNAME"); err == nil {
-		t.Errorf("Invalid Hostname Returned No Error")
-	}
-}
-
-// TestValidGetEnvValueForField3 ensures when an env var cannot be found, an error is returned
-func TestValidGetEnvValueForField3(t *testing.T) {
-	osGetenv = mockInvalidOsGetenv
-
-	if _, err := getEnvValueForField("$ENV_VAR"); err == nil {
-		t.Errorf("Invalid Hostname Returned No Error")
-	}
-}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// yamlErrorLine matches the line number prefixed to each YAML decoding error
+var yamlErrorLine = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// ValidationError describes a single problem found in a config, located by its path and position in the YAML
+type ValidationError struct {
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+	Path   string `json:"path"`
+	Msg    string `json:"message"`
+}
+
+// Error formats the error as 'file:line:column: path: message', omitting any parts which are unknown
+func (e *ValidationError) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", location, e.Line, e.Column)
+	}
+
+	parts := make([]string, 0, 3)
+	for _, part := range []string{strings.TrimPrefix(location, ":"), e.Path, e.Msg} {
+		if len(part) > 0 {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ": ")
+}
+
+// ValidationErrors holds every problem found whilst loading or validating a config
+type ValidationErrors []*ValidationError
+
+// Error returns every error on its own line
+func (e ValidationErrors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// position is the line and column of a YAML node, along with the file declaring it when a config spans several files
+type position struct {
+	Line   int
+	Column int
+	File   string
+}
+
+// errorList collects validation errors, paths are relative to the item being validated
+type errorList struct {
+	errs ValidationErrors
+}
+
+// add records an error at the given path
+func (l *errorList) add(path, format string, args ...interface{}) {
+	l.errs = append(l.errs, &ValidationError{Path: path, Msg: fmt.Sprintf(format, args...)})
+}
+
+// merge records the errors returned from validating the item at the given path
+func (l *errorList) merge(path string, err error) {
+	if err == nil {
+		return
+	}
+	if errs, valid := err.(ValidationErrors); valid {
+		for _, entry := range errs {
+			l.errs = append(l.errs, &ValidationError{Path: appendPath(path, entry.Path), Msg: entry.Msg})
+		}
+		return
+	}
+	l.add(path, "%s", err.Error())
+}
+
+// result returns the collected errors, or nil if there are none
+func (l *errorList) result() error {
+	if len(l.errs) == 0 {
+		return nil
+	}
+	return l.errs
+}
+
+/*joinPath appends keys to a config path, joining them with '.'. The path is kept as it is given, whilst a key holding a '.',
+'[' or ']', such as the URL '/v1.2/users', is quoted in brackets as in 'endpoints["/v1.2/users"].get' */
+func joinPath(path interface{}, keys ...interface{}) string {
+	result := fmt.Sprint(path)
+	for _, key := range keys {
+		part := fmt.Sprint(key)
+		if strings.ContainsAny(part, ".[]") {
+			part = "[" + strconv.Quote(part) + "]"
+		}
+		result = appendPath(result, part)
+	}
+	return result
+}
+
+// appendPath appends a path relative to the given path, such as the path of an error returned for an item within it
+func appendPath(path, relative string) string {
+	switch {
+	case len(path) == 0:
+		return relative
+	case len(relative) == 0:
+		return path
+	case strings.HasPrefix(relative, "["):
+		return path + relative
+	default:
+		return path + "." + relative
+	}
+}
+
+// parentPath returns the path holding the last key of the given path, or an empty string when it has no parent
+func parentPath(path string) string {
+	if strings.HasSuffix(path, `"]`) {
+		return path[:strings.LastIndex(path, `["`)]
+	}
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// nodePositions returns the position of every key and list item in a YAML document, keyed by config path
+func nodePositions(content []byte) map[string]position {
+	positions := make(map[string]position)
+
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &root); err != nil {
+		return positions
+	}
+
+	var walk func(node *yamlv3.Node, path string)
+	walk = func(node *yamlv3.Node, path string) {
+		switch node.Kind {
+		case yamlv3.DocumentNode:
+			for _, child := range node.Content {
+				walk(child, path)
+			}
+		case yamlv3.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := joinPath(path, node.Content[i].Value)
+				positions[key] = position{Line: node.Content[i].Line, Column: node.Content[i].Column}
+				walk(node.Content[i+1], key)
+			}
+		case yamlv3.SequenceNode:
+			for i, child := range node.Content {
+				key := joinPath(path, i)
+				positions[key] = position{Line: child.Line, Column: child.Column}
+				walk(child, key)
+			}
+		case yamlv3.AliasNode:
+			// positions within an alias are reported at the alias itself
+			aliased := make(map[string]position)
+			original := positions
+			positions = aliased
+			walk(node.Alias, path)
+			positions = original
+			for key := range aliased {
+				positions[key] = position{Line: node.Line, Column: node.Column}
+			}
+		}
+	}
+	walk(&root, "")
+
+	return positions
+}
+
+/*locate sets the file and position of each error, using the closest parent path when the path itself is not in the YAML.
+Errors holding only a line, such as decoding errors, are given the deepest path declared on that line */
+func (e ValidationErrors) locate(file string, positions map[string]position) {
+	for _, err := range e {
+		if len(err.File) == 0 {
+			err.File = file
+		}
+		if err.Line > 0 {
+			if len(err.Path) == 0 {
+				for path, pos := range positions {
+					if pos.Line == err.Line && (len(path) > len(err.Path) || (len(path) == len(err.Path) && path < err.Path)) {
+						err.Path, err.Column = path, pos.Column
+					}
+				}
+			}
+			continue
+		}
+		for path := err.Path; len(path) > 0; path = parentPath(path) {
+			if pos, found := positions[path]; found {
+				err.Line, err.Column = pos.Line, pos.Column
+				if len(pos.File) > 0 {
+					err.File = pos.File
+				}
+				break
+			}
+		}
+	}
+}
+
+// relocate sets the path of errors holding only a line from the given positions, clearing the line so they can be located by path
+func (e ValidationErrors) relocate(file string, positions map[string]position) {
+	e.locate(file, positions)
+	for _, err := range e {
+		if len(err.Path) > 0 {
+			err.Line, err.Column = 0, 0
+		}
+	}
+}
+
+// decodeErrors converts YAML decoding errors into validation errors holding their line
+func decodeErrors(err error) error {
+	typeErr, valid := err.(*yaml.TypeError)
+	if !valid {
+		return fmt.Errorf("Unable To Unmarshal Cfg: %s", err.Error())
+	}
+
+	errs := make(ValidationErrors, 0, len(typeErr.Errors))
+	for _, msg := range typeErr.Errors {
+		entry := &ValidationError{Msg: msg}
+		if match := yamlErrorLine.FindStringSubmatch(msg); match != nil {
+			entry.Line, _ = strconv.Atoi(match[1])
+			entry.Msg = match[2]
+		}
+		errs = append(errs, entry)
+	}
+	return errs
+}
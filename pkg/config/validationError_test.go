@@ -0,0 +1,120 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// testInvalidConfig has three independent errors
+const testInvalidConfig = `version: 1.0
+services:
+  testService:
+    hostname: localhost
+    port: 0
+endpoints:
+  /a:
+    get:
+      responses:
+        200:
+          weight: 50
+  /b:
+    post:
+      params:
+        query:
+          id:
+            type: number
+      response: 200
+`
+
+// TestValidate3 ensures every error is collected, each located by its config path, line and column
+func TestValidate3(t *testing.T) {
+	yamlUnmarshal = yaml.Unmarshal
+
+	cfg, err := Parse([]byte(testInvalidConfig))
+	if err != nil {
+		t.Fatalf("Error Encountered Parsing Config: %s", err.Error())
+	}
+	cfg.file = "test.yml"
+
+	errs, valid := Validate(cfg).(ValidationErrors)
+	if !valid || len(errs) != 3 {
+		t.Fatalf("Expected 3 Validation Errors, Got %v", errs)
+	}
+
+	expected := []struct {
+		path   string
+		line   int
+		column int
+	}{
+		{"services.testService.port", 5, 5},
+		{"endpoints./a.get.responses", 9, 7},
+		{"endpoints./b.post.params.query.id.type", 17, 13},
+	}
+	for i, entry := range expected {
+		if errs[i].Path != entry.path || errs[i].Line != entry.line || errs[i].Column != entry.column || errs[i].File != "test.yml" {
+			t.Errorf("Incorrect Error %d: %s", i, errs[i].Error())
+		}
+	}
+}
+
+// TestParse2 ensures decoding errors are located by the path declared on their line
+func TestParse2(t *testing.T) {
+	yamlUnmarshal = yaml.Unmarshal
+	yamlUnmarshalStrict = yaml.UnmarshalStrict
+
+	_, err := Parse([]byte("version: 2.0\nstartupActions:\n  - delay: \"5\"\n  - delay:\n      secs: 1\n"))
+	errs, valid := err.(ValidationErrors)
+	if !valid || len(errs) != 2 {
+		t.Fatalf("Expected 2 Decoding Errors, Got %v", err)
+	}
+
+	if errs[0].Path != "startupActions.0.delay" || errs[0].Line != 3 {
+		t.Errorf("Incorrect Location For Type Error: %s", errs[0].Error())
+	}
+	if errs[1].Path != "startupActions.1.delay.secs" || errs[1].Line != 5 {
+		t.Errorf("Incorrect Location For Unknown Field: %s", errs[1].Error())
+	}
+}
+
+// TestJoinPath1 ensures keys holding a '.' are bracketed, and the parent of each path is found around them
+func TestJoinPath1(t *testing.T) {
+	for _, tc := range []struct {
+		path   string
+		parent string
+	}{
+		{joinPath("endpoints", "/a", "get"), "endpoints./a"},
+		{joinPath("endpoints", "/v1.2/x", "get"), `endpoints["/v1.2/x"]`},
+		{joinPath("endpoints", "/v1.2/x"), "endpoints"},
+		{joinPath("requests", "a.b", "headers", "X.Y"), `requests["a.b"].headers`},
+		{joinPath("", "q.1", "type"), `["q.1"]`},
+		{appendPath(joinPath("services", "a.b"), "hostname"), `services["a.b"]`},
+		{"version", ""},
+	} {
+		if parent := parentPath(tc.path); parent != tc.parent {
+			t.Errorf("Parent Of %s Is %s, Expected %s", tc.path, parent, tc.parent)
+		}
+	}
+
+	if path := joinPath("endpoints", "/v1.2/x", "get"); path != `endpoints["/v1.2/x"].get` {
+		t.Errorf("Dotted Key Not Bracketed: %s", path)
+	}
+}
+
+// TestValidate4 ensures errors within keys holding a '.' are located by their own position
+func TestValidate4(t *testing.T) {
+	yamlUnmarshal = yaml.Unmarshal
+
+	cfg, err := Parse([]byte("version: 1.0\nendpoints:\n  /v1:\n    get:\n      response: 200\n  /v1.2:\n    get:\n      responses:\n        200:\n          weight: 50\n"))
+	if err != nil {
+		t.Fatalf("Error Encountered Parsing Config: %s", err.Error())
+	}
+
+	errs, valid := Validate(cfg).(ValidationErrors)
+	if !valid || len(errs) != 1 {
+		t.Fatalf("Expected 1 Validation Error, Got %v", errs)
+	}
+	if errs[0].Path != `endpoints["/v1.2"].get.responses` || errs[0].Line != 8 {
+		t.Errorf("Incorrect Error: %s", errs[0].Error())
+	}
+}
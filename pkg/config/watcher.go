@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+)
+
+// Watcher polls the files a config was loaded from, reporting when any of them are modified, added or removed
+type Watcher struct {
+	files  []string
+	stamps map[string]string // path -> modification time and size
+}
+
+// NewWatcher creates a new instance of Watcher for the given files
+func NewWatcher(files []string) *Watcher {
+	w := new(Watcher)
+	w.Watch(files)
+	return w
+}
+
+// Watch replaces the watched files, recording their current state
+func (w *Watcher) Watch(files []string) {
+	w.files = files
+	w.stamps = make(map[string]string, len(files))
+	for _, path := range files {
+		w.stamps[path] = stamp(path)
+	}
+}
+
+// Changed returns true if any watched file has changed since the last call or since it was watched
+func (w *Watcher) Changed() bool {
+	changed := false
+	for _, path := range w.files {
+		if current := stamp(path); current != w.stamps[path] {
+			w.stamps[path] = current
+			changed = true
+		}
+	}
+	return changed
+}
+
+// stamp returns the modification time and size of a file, or an empty string if it cannot be found
+func stamp(path string) string {
+	info, err := osStat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size())
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWatcher1 ensures modified, added and removed files are reported as changes once
+func TestWatcher1(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"ministub.yml": "version: 1.0\n"})
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "ministub.yml")
+	watcher := NewWatcher([]string{path})
+	if watcher.Changed() {
+		t.Errorf("Unmodified File Reported As Changed")
+	}
+
+	if err := ioutil.WriteFile(path, []byte("version: 2.0\nendpoints: {}\n"), 0644); err != nil {
+		t.Fatalf("Unable To Write File: %s", err.Error())
+	}
+	if !watcher.Changed() {
+		t.Errorf("Modified File Not Reported As Changed")
+	}
+	if watcher.Changed() {
+		t.Errorf("Change Reported Twice")
+	}
+
+	os.Remove(path)
+	if !watcher.Changed() {
+		t.Errorf("Removed File Not Reported As Changed")
+	}
+}
+
+// TestWatcher2 ensures the files a config was loaded from are returned for watching, including globbed directories
+func TestWatcher2(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"ministub.yml":     "version: 1.0\ninclude:\n  - services/*.yml\n",
+		"services/api.yml": "endpoints: {}\n",
+	})
+	defer os.RemoveAll(dir)
+
+	cfg, err := LoadFromFile(filepath.Join(dir, "ministub.yml"))
+	if err != nil {
+		t.Fatalf("Error Encountered Loading Config: %s", err.Error())
+	}
+
+	expected := []string{filepath.Join(dir, "ministub.yml"), filepath.Join(dir, "services"), filepath.Join(dir, "services", "api.yml")}
+	if files := cfg.Files(); len(files) != len(expected) {
+		t.Errorf("Unexpected Files: %v", files)
+	} else {
+		for i := range expected {
+			if files[i] != expected[i] {
+				t.Errorf("Expected File %s, Got %s", expected[i], files[i])
+			}
+		}
+	}
+}
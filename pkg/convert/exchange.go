@@ -0,0 +1,443 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// Exchange is a single captured request and the response returned for it
+type Exchange struct {
+	Method          string
+	Path            string
+	Query           url.Values
+	Headers         http.Header
+	Body            []byte
+	StatusCode      int
+	ResponseHeaders http.Header
+	ResponseBody    []byte
+}
+
+// EndpointOptions controls how exchanges are grouped into endpoints
+type EndpointOptions struct {
+	CollapseIDs  bool // replace path segments which look like IDs with ':param' segments
+	MatchHeaders bool // require request headers sent with the same value on every sample, when there are at least 2
+}
+
+// maxResponses is the most status codes a single endpoint can weight between, each weight is a multiple of 10
+const maxResponses = 10
+
+var (
+	uuidPattern    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	integerPattern = regexp.MustCompile(`^[0-9]+$`)
+	hexPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8,}$`)
+	tokenPattern   = regexp.MustCompile(`^[0-9a-zA-Z_-]{16,}$`)
+	digitPattern   = regexp.MustCompile(`[0-9]`)
+)
+
+// skippedResponseHeaders are headers which are generated per response and should not be replayed
+var skippedResponseHeaders = map[string]bool{
+	"Content-Length":    true,
+	"Date":              true,
+	"Connection":        true,
+	"Keep-Alive":        true,
+	"Transfer-Encoding": true,
+	"Content-Encoding":  true,
+	"Set-Cookie":        true,
+}
+
+// skippedRequestHeaders are headers set by clients and transports which are not meaningful to match on
+var skippedRequestHeaders = map[string]bool{
+	"Accept":            true,
+	"Accept-Encoding":   true,
+	"Accept-Language":   true,
+	"Cache-Control":     true,
+	"Connection":        true,
+	"Content-Length":    true,
+	"Cookie":            true,
+	"Host":              true,
+	"Origin":            true,
+	"Pragma":            true,
+	"Referer":           true,
+	"User-Agent":        true,
+	"X-Forwarded-For":   true,
+	"X-Forwarded-Host":  true,
+	"X-Forwarded-Proto": true,
+}
+
+// perRequestHeaders are credentials and tracing headers which change between clients or requests, so are never matched on
+var perRequestHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"X-Request-Id":        true,
+	"X-Correlation-Id":    true,
+	"X-Amzn-Trace-Id":     true,
+	"Traceparent":         true,
+	"Tracestate":          true,
+	"Baggage":             true,
+	"Uber-Trace-Id":       true,
+}
+
+// minHeaderSamples is the fewest requests a header must be seen on, with the same value, before it is matched on
+const minHeaderSamples = 2
+
+// endpointSamples holds every exchange grouped under a single url pattern and method
+type endpointSamples struct {
+	pathParams map[string]string // name -> type
+	exchanges  []*Exchange
+}
+
+// BuildEndpoints groups the given exchanges by method and path, generating a v1 endpoint definition for each
+func BuildEndpoints(exchanges []*Exchange, opts *EndpointOptions) map[string]map[string]*config.Endpoint {
+	if opts == nil {
+		opts = &EndpointOptions{}
+	}
+
+	grouped := make(map[string]map[string]*endpointSamples)
+	for _, exchange := range exchanges {
+		pattern, pathParams := exchange.Path, map[string]string{}
+		if opts.CollapseIDs {
+			pattern, pathParams = CollapsePath(exchange.Path)
+		}
+		method := strings.ToLower(exchange.Method)
+
+		if _, found := grouped[pattern]; !found {
+			grouped[pattern] = make(map[string]*endpointSamples)
+		}
+		samples, found := grouped[pattern][method]
+		if !found {
+			samples = &endpointSamples{pathParams: pathParams}
+			grouped[pattern][method] = samples
+		}
+		for name, paramType := range pathParams {
+			// a parameter seen with differing types can only be validated as a string
+			if samples.pathParams[name] != paramType {
+				samples.pathParams[name] = "string"
+			}
+		}
+		samples.exchanges = append(samples.exchanges, exchange)
+	}
+
+	endpoints := make(map[string]map[string]*config.Endpoint, len(grouped))
+	for pattern, methods := range grouped {
+		endpoints[pattern] = make(map[string]*config.Endpoint, len(methods))
+		for method, samples := range methods {
+			endpoints[pattern][method] = buildEndpoint(samples, opts)
+		}
+	}
+	return endpoints
+}
+
+// buildEndpoint generates a single endpoint definition from its samples
+func buildEndpoint(samples *endpointSamples, opts *EndpointOptions) *config.Endpoint {
+	entry := &config.Endpoint{}
+	params := &config.Parameters{}
+
+	if len(samples.pathParams) > 0 {
+		params.Path = make(map[string]*config.ParamEntry, len(samples.pathParams))
+		for name, paramType := range samples.pathParams {
+			params.Path[name] = &config.ParamEntry{Type: paramType, Required: true}
+		}
+	}
+
+	// query params seen on every request are required, their type is the loosest seen
+	queryCounts := make(map[string]int)
+	queryTypes := make(map[string]string)
+	for _, exchange := range samples.exchanges {
+		for name, values := range exchange.Query {
+			if len(values) == 0 {
+				continue
+			}
+			queryCounts[name]++
+			queryTypes[name] = mergeType(queryTypes[name], InferType(values[0]))
+		}
+	}
+	if len(queryCounts) > 0 {
+		params.Query = make(map[string]*config.ParamEntry, len(queryCounts))
+		for name, count := range queryCounts {
+			params.Query[name] = &config.ParamEntry{Type: queryTypes[name], Required: count == len(samples.exchanges)}
+		}
+	}
+
+	if params.Path != nil || params.Query != nil {
+		entry.Params = params
+	}
+	entry.Recieves = buildRecieves(samples.exchanges, opts.MatchHeaders)
+
+	// the latest response seen for each status code is replayed
+	counts := make(map[int]int)
+	latest := make(map[int]*Exchange)
+	for _, exchange := range samples.exchanges {
+		counts[exchange.StatusCode]++
+		latest[exchange.StatusCode] = exchange
+	}
+
+	weights := WeightResponses(counts)
+	entry.Responses = make(map[int]*config.Response, len(weights))
+	for statusCode, weight := range weights {
+		resp := BuildResponse(latest[statusCode].ResponseHeaders, latest[statusCode].ResponseBody)
+		resp.Weight = weight
+		entry.Responses[statusCode] = resp
+	}
+
+	return entry
+}
+
+/*buildRecieves generates the top-level JSON body fields which were the same on every request, and when matchHeaders is set
+the headers which were too */
+func buildRecieves(exchanges []*Exchange, matchHeaders bool) *config.Recieves {
+	var headers map[string]string
+	if matchHeaders && len(exchanges) >= minHeaderSamples {
+		headers = commonHeaders(exchanges)
+	}
+	fields := commonBodyFields(exchanges)
+
+	if len(headers) == 0 && len(fields) == 0 {
+		return nil
+	}
+	return &config.Recieves{Headers: headers, Body: fields}
+}
+
+// commonHeaders returns the request headers sent with the same value on every request, other than credentials and tracing
+func commonHeaders(exchanges []*Exchange) map[string]string {
+	var headers map[string]string
+
+	for _, exchange := range exchanges {
+		found := make(map[string]string, len(exchange.Headers))
+		for name, values := range exchange.Headers {
+			name = http.CanonicalHeaderKey(name)
+			if len(values) == 0 || skippedRequestHeaders[name] || perRequestHeaders[name] || strings.HasPrefix(name, ":") || strings.HasPrefix(name, "Sec-") {
+				continue
+			}
+			found[name] = values[0]
+		}
+
+		if headers == nil {
+			headers = found
+			continue
+		}
+		for name, value := range headers {
+			if found[name] != value {
+				delete(headers, name)
+			}
+		}
+	}
+
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// commonBodyFields returns the top-level JSON body fields sent with the same type on every request
+func commonBodyFields(exchanges []*Exchange) map[string]string {
+	var fields map[string]string
+
+	for _, exchange := range exchanges {
+		var body map[string]interface{}
+		if len(exchange.Body) == 0 || json.Unmarshal(exchange.Body, &body) != nil {
+			return nil
+		}
+
+		found := make(map[string]string, len(body))
+		for name, value := range body {
+			if valueType := JSONType(value); len(valueType) > 0 {
+				found[name] = valueType
+			}
+		}
+
+		if fields == nil {
+			fields = found
+			continue
+		}
+		for name, valueType := range fields {
+			if found[name] != valueType {
+				delete(fields, name)
+			}
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// BuildResponse generates a response definition replaying the given headers and body
+func BuildResponse(headers http.Header, body []byte) *config.Response {
+	resp := &config.Response{}
+
+	for name, values := range headers {
+		name = http.CanonicalHeaderKey(name)
+		if len(values) == 0 || skippedResponseHeaders[name] {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = make(map[string]string)
+		}
+		resp.Headers[name] = values[0]
+	}
+
+	if len(body) > 0 {
+		var jsonBody map[string]interface{}
+		if json.Unmarshal(body, &jsonBody) == nil && len(jsonBody) > 0 {
+			resp.Body = jsonBody
+		} else {
+			resp.RawBody = EscapeTemplate(string(body))
+		}
+	}
+
+	return resp
+}
+
+// EscapeTemplate escapes any template actions in the given text so it is returned as-is from a rawBody
+func EscapeTemplate(text string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	return strings.Replace(text, "{{", `{{"{{"}}`, -1)
+}
+
+/*WeightResponses converts the number of times each status code was seen into weights summing to 100
+weights are multiples of 10 as responses are chosen from 10 slots, only the 10 most common status codes are kept */
+func WeightResponses(counts map[int]int) map[int]int {
+	statusCodes := make([]int, 0, len(counts))
+	for statusCode := range counts {
+		statusCodes = append(statusCodes, statusCode)
+	}
+	sort.Slice(statusCodes, func(i, j int) bool {
+		if counts[statusCodes[i]] == counts[statusCodes[j]] {
+			return statusCodes[i] < statusCodes[j]
+		}
+		return counts[statusCodes[i]] > counts[statusCodes[j]]
+	})
+	if len(statusCodes) > maxResponses {
+		statusCodes = statusCodes[:maxResponses]
+	}
+
+	total := 0
+	for _, statusCode := range statusCodes {
+		total += counts[statusCode]
+	}
+
+	// every status code gets at least one slot, the rest are shared by largest remainder
+	slots := make(map[int]int, len(statusCodes))
+	remainders := make(map[int]int, len(statusCodes))
+	free := maxResponses - len(statusCodes)
+	used := 0
+	for _, statusCode := range statusCodes {
+		share := counts[statusCode] * free
+		slots[statusCode] = 1 + share/total
+		remainders[statusCode] = share % total
+		used += share / total
+	}
+	sort.SliceStable(statusCodes, func(i, j int) bool { return remainders[statusCodes[i]] > remainders[statusCodes[j]] })
+	for i := 0; used < free; i++ {
+		slots[statusCodes[i%len(statusCodes)]]++
+		used++
+	}
+
+	weights := make(map[int]int, len(slots))
+	for statusCode, count := range slots {
+		weights[statusCode] = count * 10
+	}
+	return weights
+}
+
+// CollapsePath replaces path segments which look like IDs with ':param' segments, returning the pattern and the type of each param
+func CollapsePath(path string) (string, map[string]string) {
+	segments := strings.Split(path, "/")
+	params := make(map[string]string)
+
+	for i, segment := range segments {
+		paramType := idType(segment)
+		if len(paramType) == 0 {
+			continue
+		}
+
+		name := "id"
+		if i > 0 && len(segments[i-1]) > 0 && segments[i-1][0] != ':' {
+			name = fmt.Sprintf("%s_id", strings.TrimSuffix(segments[i-1], "s"))
+		}
+		for suffix := 2; len(params[name]) > 0; suffix++ {
+			name = fmt.Sprintf("%s%d", strings.TrimRight(name, "0123456789"), suffix)
+		}
+
+		params[name] = paramType
+		segments[i] = ":" + name
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// idType returns the param type for a path segment which looks like an ID, or an empty string if it does not
+func idType(segment string) string {
+	switch {
+	case integerPattern.MatchString(segment):
+		return "integer"
+	case uuidPattern.MatchString(segment):
+		return "string"
+	case hexPattern.MatchString(segment) && digitPattern.MatchString(segment):
+		return "string"
+	case tokenPattern.MatchString(segment) && digitPattern.MatchString(segment):
+		return "string"
+	default:
+		return ""
+	}
+}
+
+// InferType returns the ministub type for a string value such as a query parameter
+func InferType(value string) string {
+	lower := strings.ToLower(value)
+	switch {
+	case integerPattern.MatchString(value):
+		return "integer"
+	case lower == "true" || lower == "false":
+		return "boolean"
+	default:
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return "float"
+		}
+		return "string"
+	}
+}
+
+// JSONType returns the ministub type for a decoded JSON value, or an empty string for null
+func JSONType(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "float"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return ""
+	}
+}
+
+// mergeType returns a type which accepts values of both given types
+func mergeType(current, next string) string {
+	switch {
+	case len(current) == 0 || current == next:
+		return next
+	case (current == "integer" && next == "float") || (current == "float" && next == "integer"):
+		return "float"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,118 @@
+package convert
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// TestCollapsePath1 ensures ID-like segments are replaced with typed params named after the previous segment
+func TestCollapsePath1(t *testing.T) {
+	pattern, params := CollapsePath("/api/v1/users/42/orders/3f2b8c1e-5d4a-4f7e-9a1b-2c3d4e5f6a7b")
+
+	if pattern != "/api/v1/users/:user_id/orders/:order_id" {
+		t.Errorf("Unexpected Pattern: %s", pattern)
+	}
+	if params["user_id"] != "integer" {
+		t.Errorf("Param user_id Is Not integer: %s", params["user_id"])
+	}
+	if params["order_id"] != "string" {
+		t.Errorf("Param order_id Is Not string: %s", params["order_id"])
+	}
+}
+
+// TestCollapsePath2 ensures static segments are left unchanged
+func TestCollapsePath2(t *testing.T) {
+	for _, path := range []string{"/api/v1/users", "/health", "/api/v2/reports/latest"} {
+		if pattern, params := CollapsePath(path); pattern != path || len(params) != 0 {
+			t.Errorf("Static Path %s Was Collapsed To %s", path, pattern)
+		}
+	}
+}
+
+// TestWeightResponses1 ensures weights are multiples of 10 summing to 100, with every status code kept
+func TestWeightResponses1(t *testing.T) {
+	for _, counts := range []map[int]int{
+		{200: 1},
+		{200: 7, 500: 3},
+		{200: 98, 404: 1, 500: 1},
+		{200: 1, 201: 1, 202: 1},
+	} {
+		weights := WeightResponses(counts)
+		total := 0
+		for statusCode, weight := range weights {
+			if weight < 10 || weight%10 != 0 {
+				t.Errorf("Weight For %d Is Invalid: %d", statusCode, weight)
+			}
+			total += weight
+		}
+		if total != 100 || len(weights) != len(counts) {
+			t.Errorf("Weights %v For Counts %v Are Invalid", weights, counts)
+		}
+	}
+}
+
+// TestBuildEndpoints1 ensures exchanges are grouped into a valid config with params, body fields and weighted responses
+func TestBuildEndpoints1(t *testing.T) {
+	exchanges := []*Exchange{
+		{Method: "POST", Path: "/jobs/2", Body: []byte(`{"uid":"b"}`), StatusCode: 202},
+		{Method: "POST", Path: "/jobs/1", Query: url.Values{"dry": {"true"}}, Body: []byte(`{"uid":"a","n":1}`), StatusCode: 202, ResponseBody: []byte(`{"ok":true}`), ResponseHeaders: http.Header{"Content-Type": {"application/json"}, "Date": {"now"}}},
+		{Method: "POST", Path: "/jobs/3", Body: []byte(`{"uid":"c"}`), StatusCode: 500, ResponseBody: []byte("{{oops}}")},
+	}
+
+	endpoints := BuildEndpoints(exchanges, &EndpointOptions{CollapseIDs: true})
+	entry := endpoints["/jobs/:job_id"]["post"]
+	if entry == nil {
+		t.Fatalf("Endpoint /jobs/:job_id Not Generated: %v", endpoints)
+	}
+
+	if entry.Params.Query["dry"].Required || entry.Params.Query["dry"].Type != "boolean" {
+		t.Errorf("Query Param dry Is Invalid: %+v", entry.Params.Query["dry"])
+	}
+	if len(entry.Recieves.Body) != 1 || entry.Recieves.Body["uid"] != "string" {
+		t.Errorf("Recieves Body Is Invalid: %v", entry.Recieves.Body)
+	}
+	// the latest 202 is replayed, which carries both a Content-Type and a Date header
+	if entry.Responses[202].Headers["Content-Type"] != "application/json" {
+		t.Errorf("Content-Type Header Was Not Replayed: %v", entry.Responses[202].Headers)
+	}
+	if _, found := entry.Responses[202].Headers["Date"]; found {
+		t.Errorf("Date Header Was Not Skipped")
+	}
+	if entry.Responses[500].RawBody != `{{"{{"}}oops}}` {
+		t.Errorf("Raw Body Was Not Escaped: %s", entry.Responses[500].RawBody)
+	}
+
+	if err := config.Validate(&config.Config{Version: 1.0, Endpoints: endpoints}); err != nil {
+		t.Errorf("Generated Config Is Invalid: %s", err.Error())
+	}
+}
+
+// TestBuildEndpoints2 ensures headers are only matched when asked, once seen twice, and never credentials or tracing headers
+func TestBuildEndpoints2(t *testing.T) {
+	single := []*Exchange{
+		{Method: "GET", Path: "/one", Headers: http.Header{"X-Tenant": {"a"}}, StatusCode: 200},
+	}
+	repeated := []*Exchange{
+		{Method: "GET", Path: "/two", Headers: http.Header{"X-Tenant": {"a"}, "Authorization": {"Bearer t"}, "Traceparent": {"1"}}, StatusCode: 200},
+		{Method: "GET", Path: "/two", Headers: http.Header{"X-Tenant": {"a"}, "Authorization": {"Bearer t"}, "Traceparent": {"2"}}, StatusCode: 200},
+	}
+	exchanges := append(single, repeated...)
+
+	for path, entry := range BuildEndpoints(exchanges, nil) {
+		if entry["get"].Recieves != nil {
+			t.Errorf("Headers Matched On %s Without MatchHeaders: %v", path, entry["get"].Recieves.Headers)
+		}
+	}
+
+	endpoints := BuildEndpoints(exchanges, &EndpointOptions{MatchHeaders: true})
+	if endpoints["/one"]["get"].Recieves != nil {
+		t.Errorf("Headers Matched On A Single Sample: %v", endpoints["/one"]["get"].Recieves.Headers)
+	}
+	recieves := endpoints["/two"]["get"].Recieves
+	if recieves == nil || len(recieves.Headers) != 1 || recieves.Headers["X-Tenant"] != "a" {
+		t.Errorf("Only X-Tenant Should Be Matched: %+v", recieves)
+	}
+}
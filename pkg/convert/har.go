@@ -0,0 +1,144 @@
+package convert
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// harFile represents the parts of an HTTP Archive used for importing
+type harFile struct {
+	Log struct {
+		Entries []*harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+// harEntry represents a single request and response within an HTTP Archive
+type harEntry struct {
+	Request struct {
+		Method      string          `json:"method"`
+		URL         string          `json:"url"`
+		Headers     []*harNameValue `json:"headers"`
+		QueryString []*harNameValue `json:"queryString"`
+		PostData    *struct {
+			Text string `json:"text"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Status  int             `json:"status"`
+		Headers []*harNameValue `json:"headers"`
+		Content struct {
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+// harNameValue represents a header or query string entry within an HTTP Archive
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HAROptions controls which HAR entries are imported and how they are grouped
+type HAROptions struct {
+	Host         string // only import entries sent to this host, matched with or without a port
+	Dedupe       bool   // collapse paths which differ only by ID segments into a single ':param' endpoint
+	MatchHeaders bool   // require request headers sent identically on every call to an endpoint
+}
+
+// FromHAR generates a v1 config with an endpoint for every distinct request in the given HTTP Archive
+func FromHAR(data []byte, opts *HAROptions) (*config.Config, error) {
+	if opts == nil {
+		opts = &HAROptions{}
+	}
+
+	har := new(harFile)
+	if err := json.Unmarshal(data, har); err != nil {
+		return nil, fmt.Errorf("Unable To Unmarshal HAR: %s", err.Error())
+	}
+
+	exchanges := make([]*Exchange, 0, len(har.Log.Entries))
+	for i, entry := range har.Log.Entries {
+		reqURL, err := url.Parse(entry.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid URL For Entry %d: %s", i, err.Error())
+		}
+		if len(opts.Host) > 0 && reqURL.Host != opts.Host && reqURL.Hostname() != opts.Host {
+			continue
+		}
+		// entries for aborted or blocked requests have no status
+		if entry.Response.Status <= 0 {
+			continue
+		}
+
+		exchange, err := harExchange(entry, reqURL)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid Entry %d: %s", i, err.Error())
+		}
+		exchanges = append(exchanges, exchange)
+	}
+
+	if len(exchanges) == 0 {
+		return nil, fmt.Errorf("No Entries To Import")
+	}
+
+	cfg := &config.Config{
+		Version:   1.0,
+		Endpoints: BuildEndpoints(exchanges, &EndpointOptions{CollapseIDs: opts.Dedupe, MatchHeaders: opts.MatchHeaders}),
+	}
+	if err := config.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("Generated Config Is Invalid: %s", err.Error())
+	}
+
+	return cfg, nil
+}
+
+// harExchange converts a HAR entry into an Exchange
+func harExchange(entry *harEntry, reqURL *url.URL) (*Exchange, error) {
+	exchange := &Exchange{
+		Method:          entry.Request.Method,
+		Path:            reqURL.Path,
+		Query:           reqURL.Query(),
+		Headers:         harHeaders(entry.Request.Headers),
+		StatusCode:      entry.Response.Status,
+		ResponseHeaders: harHeaders(entry.Response.Headers),
+	}
+
+	if len(exchange.Path) == 0 {
+		exchange.Path = "/"
+	}
+	for _, param := range entry.Request.QueryString {
+		if _, found := exchange.Query[param.Name]; !found {
+			exchange.Query.Add(param.Name, param.Value)
+		}
+	}
+	if entry.Request.PostData != nil {
+		exchange.Body = []byte(entry.Request.PostData.Text)
+	}
+
+	exchange.ResponseBody = []byte(entry.Response.Content.Text)
+	if strings.ToLower(entry.Response.Content.Encoding) == "base64" {
+		body, err := base64.StdEncoding.DecodeString(entry.Response.Content.Text)
+		if err != nil {
+			return nil, fmt.Errorf("Unable To Decode Response Body: %s", err.Error())
+		}
+		exchange.ResponseBody = body
+	}
+
+	return exchange, nil
+}
+
+// harHeaders converts a list of HAR headers into an http.Header
+func harHeaders(entries []*harNameValue) http.Header {
+	headers := make(http.Header, len(entries))
+	for _, entry := range entries {
+		headers.Add(entry.Name, entry.Value)
+	}
+	return headers
+}
@@ -0,0 +1,80 @@
+package convert
+
+import "testing"
+
+// testHAR is a session with two calls to an API host and one to a CDN host
+const testHAR = `{"log":{"entries":[
+	{"request":{"method":"GET","url":"https://api.example.com/v1/users/12?expand=true","headers":[{"name":"user-agent","value":"x"},{"name":"x-api-key","value":"k"},{"name":"authorization","value":"Bearer t"}],"queryString":[{"name":"expand","value":"true"}]},
+	 "response":{"status":200,"headers":[{"name":"content-type","value":"application/json"}],"content":{"text":"{\"id\":12}"}}},
+	{"request":{"method":"GET","url":"https://api.example.com/v1/users/13","headers":[{"name":"x-api-key","value":"k"},{"name":"authorization","value":"Bearer t"}]},
+	 "response":{"status":404,"headers":[],"content":{"text":"bm90IGZvdW5k","encoding":"base64"}}},
+	{"request":{"method":"GET","url":"https://cdn.example.com/logo.png"},
+	 "response":{"status":200,"content":{"text":""}}}
+]}}`
+
+// TestFromHAR1 ensures entries are filtered by host and de-duplicated by path pattern, matching common headers when asked
+func TestFromHAR1(t *testing.T) {
+	cfg, err := FromHAR([]byte(testHAR), &HAROptions{Host: "api.example.com", Dedupe: true, MatchHeaders: true})
+	if err != nil {
+		t.Fatalf("Error Encountered Importing HAR: %s", err.Error())
+	}
+
+	if len(cfg.Endpoints) != 1 {
+		t.Fatalf("Unexpected Endpoint Count: %d", len(cfg.Endpoints))
+	}
+
+	entry := cfg.Endpoints["/v1/users/:user_id"]["get"]
+	if entry == nil {
+		t.Fatalf("Endpoint /v1/users/:user_id Not Generated")
+	}
+	if entry.Recieves == nil || entry.Recieves.Headers["X-Api-Key"] != "k" {
+		t.Errorf("Common Request Header Not Added To Recieves")
+	}
+	if entry.Recieves != nil && len(entry.Recieves.Headers["User-Agent"]) > 0 {
+		t.Errorf("User-Agent Header Was Not Skipped")
+	}
+	if entry.Recieves != nil && len(entry.Recieves.Headers["Authorization"]) > 0 {
+		t.Errorf("Authorization Header Was Not Skipped")
+	}
+	if entry.Responses[404] == nil || entry.Responses[404].RawBody != "not found" {
+		t.Errorf("Base64 Response Body Not Decoded")
+	}
+	if entry.Responses[200].Weight+entry.Responses[404].Weight != 100 {
+		t.Errorf("Response Weights Do Not Equal 100")
+	}
+}
+
+// TestFromHAR2 ensures every path is kept without de-duplication, and headers are not matched unless asked
+func TestFromHAR2(t *testing.T) {
+	cfg, err := FromHAR([]byte(testHAR), nil)
+	if err != nil {
+		t.Fatalf("Error Encountered Importing HAR: %s", err.Error())
+	}
+
+	for _, path := range []string{"/v1/users/12", "/v1/users/13", "/logo.png"} {
+		if _, found := cfg.Endpoints[path]; !found {
+			t.Errorf("Endpoint %s Not Generated", path)
+		}
+	}
+
+	cfg, err = FromHAR([]byte(testHAR), &HAROptions{Dedupe: true})
+	if err != nil {
+		t.Fatalf("Error Encountered Importing HAR: %s", err.Error())
+	}
+	if entry := cfg.Endpoints["/v1/users/:user_id"]["get"]; entry == nil || entry.Recieves != nil {
+		t.Errorf("Request Headers Were Matched Without MatchHeaders: %+v", entry)
+	}
+}
+
+// TestFromHAR3 ensures invalid or empty archives are returned as errors
+func TestFromHAR3(t *testing.T) {
+	for _, data := range []string{"", "{", `{"log":{"entries":[]}}`} {
+		if _, err := FromHAR([]byte(data), nil); err == nil {
+			t.Errorf("Invalid HAR '%s' Returned No Error", data)
+		}
+	}
+
+	if _, err := FromHAR([]byte(testHAR), &HAROptions{Host: "other.example.com"}); err == nil {
+		t.Errorf("HAR With No Matching Host Returned No Error")
+	}
+}
@@ -0,0 +1,444 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+// openAPIMethods are the operation keys of an OpenAPI path item which map to endpoint methods
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+// openAPIPathParam matches a '{name}' path template segment
+var openAPIPathParam = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// openAPIDoc wraps a decoded OpenAPI document to resolve local '$ref' pointers
+type openAPIDoc struct {
+	root map[string]interface{}
+}
+
+// IsOpenAPI returns whether the given YAML or JSON document is an OpenAPI 3 specification
+func IsOpenAPI(data []byte) bool {
+	probe := struct {
+		OpenAPI string `yaml:"openapi"`
+	}{}
+	return yaml.Unmarshal(data, &probe) == nil && strings.HasPrefix(probe.OpenAPI, "3.")
+}
+
+/*FromOpenAPI generates a v1 config with an endpoint for every operation in the given OpenAPI 3 specification
+each documented status code becomes a response weighted 10, with the lowest 2xx response weighted the remainder */
+func FromOpenAPI(data []byte) (*config.Config, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("Unable To Unmarshal OpenAPI Spec: %s", err.Error())
+	}
+
+	root, valid := stringKeys(raw).(map[string]interface{})
+	if !valid || !strings.HasPrefix(fmt.Sprint(root["openapi"]), "3.") {
+		return nil, fmt.Errorf("Document Is Not An OpenAPI 3 Specification")
+	}
+	doc := &openAPIDoc{root: root}
+
+	paths := asMap(root["paths"])
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("No Paths Defined")
+	}
+
+	basePath := doc.basePath()
+	endpoints := make(map[string]map[string]*config.Endpoint, len(paths))
+	for path, rawItem := range paths {
+		item := asMap(doc.resolve(rawItem))
+		url := basePath + openAPIPathParam.ReplaceAllString(path, ":$1")
+
+		for _, method := range openAPIMethods {
+			operation := asMap(doc.resolve(item[method]))
+			if operation == nil {
+				continue
+			}
+
+			entry, err := doc.buildEndpoint(item, operation)
+			if err != nil {
+				return nil, fmt.Errorf("Operation %s %s: %s", strings.ToUpper(method), path, err.Error())
+			}
+			if _, found := endpoints[url]; !found {
+				endpoints[url] = make(map[string]*config.Endpoint)
+			}
+			endpoints[url][method] = entry
+		}
+	}
+
+	cfg := &config.Config{Version: 1.0, Endpoints: endpoints}
+	if err := config.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("Generated Config Is Invalid: %s", err.Error())
+	}
+	return cfg, nil
+}
+
+/*basePath returns the path of the first server URL, which every path is served under, with any server variables replaced by
+their defaults. It is empty when there are no servers or the first is served from the root */
+func (doc *openAPIDoc) basePath() string {
+	servers := asSlice(doc.root["servers"])
+	if len(servers) == 0 {
+		return ""
+	}
+	server := asMap(servers[0])
+
+	rawURL := fmt.Sprint(server["url"])
+	for name, variable := range asMap(server["variables"]) {
+		rawURL = strings.Replace(rawURL, "{"+name+"}", fmt.Sprint(asMap(variable)["default"]), -1)
+	}
+
+	serverURL, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(serverURL.Path, "/")
+}
+
+// buildEndpoint generates an endpoint from an operation and the path item holding it
+func (doc *openAPIDoc) buildEndpoint(item, operation map[string]interface{}) (*config.Endpoint, error) {
+	entry := &config.Endpoint{}
+
+	// operation parameters override path item parameters of the same name and location
+	params := make(map[string]map[string]interface{})
+	for _, source := range []interface{}{item["parameters"], operation["parameters"]} {
+		for _, rawParam := range asSlice(source) {
+			param := asMap(doc.resolve(rawParam))
+			params[fmt.Sprintf("%s:%s", param["in"], param["name"])] = param
+		}
+	}
+
+	for _, param := range params {
+		name, _ := param["name"].(string)
+		paramEntry := &config.ParamEntry{
+			Type:     doc.schemaType(param["schema"]),
+			Required: param["required"] == true,
+		}
+
+		switch {
+		case param["in"] == "path":
+			if entry.Params == nil {
+				entry.Params = &config.Parameters{}
+			}
+			if entry.Params.Path == nil {
+				entry.Params.Path = make(map[string]*config.ParamEntry)
+			}
+			paramEntry.Required = true
+			entry.Params.Path[name] = paramEntry
+		case param["in"] == "query":
+			if entry.Params == nil {
+				entry.Params = &config.Parameters{}
+			}
+			if entry.Params.Query == nil {
+				entry.Params.Query = make(map[string]*config.ParamEntry)
+			}
+			entry.Params.Query[name] = paramEntry
+		}
+	}
+
+	if requestBody := asMap(doc.resolve(operation["requestBody"])); requestBody != nil {
+		if mediaType, media := selectMediaType(asMap(requestBody["content"])); media != nil && isJSONMediaType(mediaType) {
+			fields := make(map[string]string)
+			doc.requiredFields("", media["schema"], fields, 0)
+			if len(fields) > 0 {
+				entry.Recieves = &config.Recieves{Body: fields}
+			}
+		}
+	}
+
+	responses := asMap(operation["responses"])
+	statusCodes := make([]int, 0, len(responses))
+	for code := range responses {
+		// ranges such as 2XX are served as their first code, 'default' has no code to serve
+		statusCode, err := strconv.Atoi(strings.Replace(strings.ToUpper(code), "XX", "00", 1))
+		if err != nil || statusCode < 100 || statusCode > 599 {
+			continue
+		}
+		if _, found := responses[strconv.Itoa(statusCode)]; found && strconv.Itoa(statusCode) != code {
+			continue
+		}
+		statusCodes = append(statusCodes, statusCode)
+
+		resp, err := doc.buildResponse(responses[code])
+		if err != nil {
+			return nil, fmt.Errorf("Response %s: %s", code, err.Error())
+		}
+		if entry.Responses == nil {
+			entry.Responses = make(map[int]*config.Response)
+		}
+		entry.Responses[statusCode] = resp
+	}
+
+	if len(statusCodes) == 0 {
+		return nil, fmt.Errorf("No Responses With A Status Code Defined")
+	}
+
+	sort.Ints(statusCodes)
+	weighted := statusCodes[0]
+	for _, statusCode := range statusCodes {
+		if statusCode >= 200 && statusCode < 300 {
+			weighted = statusCode
+			break
+		}
+	}
+	// every other response is returned occasionally, as a response weighted 0 is never returned and is reported by lint
+	entry.Responses[weighted].Weight = 100
+	for _, statusCode := range statusCodes {
+		if statusCode != weighted && entry.Responses[weighted].Weight > 10 {
+			entry.Responses[statusCode].Weight = 10
+			entry.Responses[weighted].Weight -= 10
+		}
+	}
+
+	return entry, nil
+}
+
+// buildResponse generates a response from its OpenAPI definition, using the first example found as the body
+func (doc *openAPIDoc) buildResponse(rawResp interface{}) (*config.Response, error) {
+	resp := &config.Response{}
+	definition := asMap(doc.resolve(rawResp))
+
+	mediaType, media := selectMediaType(asMap(definition["content"]))
+	if media == nil {
+		return resp, nil
+	}
+
+	resp.Headers = map[string]string{"Content-Type": mediaType}
+	example, found := doc.example(media)
+	if !found {
+		return resp, nil
+	}
+
+	if body, valid := example.(map[string]interface{}); valid && isJSONMediaType(mediaType) {
+		resp.Body = body
+		return resp, nil
+	}
+
+	if text, valid := example.(string); valid && !isJSONMediaType(mediaType) {
+		resp.RawBody = EscapeTemplate(text)
+		return resp, nil
+	}
+
+	data, err := json.Marshal(example)
+	if err != nil {
+		return nil, fmt.Errorf("Unable To Marshal Example: %s", err.Error())
+	}
+	resp.RawBody = EscapeTemplate(string(data))
+	return resp, nil
+}
+
+// example returns the example for a media type, from 'example', the first of 'examples', or the schema
+func (doc *openAPIDoc) example(media map[string]interface{}) (interface{}, bool) {
+	if example, found := media["example"]; found {
+		return example, true
+	}
+
+	if examples := asMap(media["examples"]); len(examples) > 0 {
+		names := make([]string, 0, len(examples))
+		for name := range examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if value, found := asMap(doc.resolve(examples[names[0]]))["value"]; found {
+			return value, true
+		}
+	}
+
+	return doc.schemaExample(media["schema"], 0)
+}
+
+// schemaExample generates an example from a schema's 'example' and 'default' values, recursing into object properties and array items
+func (doc *openAPIDoc) schemaExample(rawSchema interface{}, depth int) (interface{}, bool) {
+	schema := asMap(doc.resolve(rawSchema))
+	if schema == nil || depth > 10 {
+		return nil, false
+	}
+
+	for _, key := range []string{"example", "default"} {
+		if value, found := schema[key]; found {
+			return value, true
+		}
+	}
+	if examples := asSlice(schema["examples"]); len(examples) > 0 {
+		return examples[0], true
+	}
+	if enum := asSlice(schema["enum"]); len(enum) > 0 {
+		return enum[0], true
+	}
+
+	switch {
+	case len(asSlice(schema["allOf"])) > 0:
+		result := make(map[string]interface{})
+		for _, part := range asSlice(schema["allOf"]) {
+			if value, found := doc.schemaExample(part, depth+1); found {
+				if object, valid := value.(map[string]interface{}); valid {
+					for k, v := range object {
+						result[k] = v
+					}
+				}
+			}
+		}
+		return result, len(result) > 0
+	case len(asSlice(schema["oneOf"])) > 0:
+		return doc.schemaExample(asSlice(schema["oneOf"])[0], depth+1)
+	case len(asSlice(schema["anyOf"])) > 0:
+		return doc.schemaExample(asSlice(schema["anyOf"])[0], depth+1)
+	case doc.schemaType(schema) == "object":
+		result := make(map[string]interface{})
+		for name, property := range asMap(schema["properties"]) {
+			if value, found := doc.schemaExample(property, depth+1); found {
+				result[name] = value
+			}
+		}
+		return result, len(result) > 0
+	case doc.schemaType(schema) == "array":
+		if value, found := doc.schemaExample(schema["items"], depth+1); found {
+			return []interface{}{value}, true
+		}
+	}
+
+	return nil, false
+}
+
+// requiredFields adds the dotted path and type of every required property of a schema to fields, recursing into required objects
+func (doc *openAPIDoc) requiredFields(prefix string, rawSchema interface{}, fields map[string]string, depth int) {
+	schema := asMap(doc.resolve(rawSchema))
+	if schema == nil || depth > 10 {
+		return
+	}
+
+	for _, part := range asSlice(schema["allOf"]) {
+		doc.requiredFields(prefix, part, fields, depth+1)
+	}
+
+	properties := asMap(schema["properties"])
+	for _, rawName := range asSlice(schema["required"]) {
+		name, valid := rawName.(string)
+		property, found := properties[name]
+		if !valid || !found {
+			continue
+		}
+
+		path := name
+		if len(prefix) > 0 {
+			path = fmt.Sprintf("%s.%s", prefix, name)
+		}
+
+		fields[path] = doc.schemaType(property)
+		if fields[path] == "object" && len(asSlice(asMap(doc.resolve(property))["required"])) > 0 {
+			delete(fields, path)
+			doc.requiredFields(path, property, fields, depth+1)
+		}
+	}
+}
+
+// schemaType returns the ministub type for a schema, defaulting to string
+func (doc *openAPIDoc) schemaType(rawSchema interface{}) string {
+	schema := asMap(doc.resolve(rawSchema))
+
+	schemaType := schema["type"]
+	// OpenAPI 3.1 allows a list of types, such as [string, "null"]
+	for _, entry := range asSlice(schemaType) {
+		if entry != "null" {
+			schemaType = entry
+			break
+		}
+	}
+
+	switch {
+	case schemaType == "integer":
+		return "integer"
+	case schemaType == "number":
+		return "float"
+	case schemaType == "boolean":
+		return "boolean"
+	case schemaType == "array":
+		return "array"
+	case schemaType == "object" || (schemaType == nil && (schema["properties"] != nil || schema["allOf"] != nil)):
+		return "object"
+	default:
+		return "string"
+	}
+}
+
+// resolve follows a local '$ref' pointer such as '#/components/schemas/User', returning the value unchanged if it holds no reference
+func (doc *openAPIDoc) resolve(value interface{}) interface{} {
+	for depth := 0; depth < 20; depth++ {
+		ref, found := asMap(value)["$ref"].(string)
+		if !found || !strings.HasPrefix(ref, "#/") {
+			return value
+		}
+
+		var current interface{} = doc.root
+		for _, segment := range strings.Split(ref[2:], "/") {
+			segment = strings.Replace(strings.Replace(segment, "~1", "/", -1), "~0", "~", -1)
+			current = asMap(current)[segment]
+		}
+		value = current
+	}
+	return nil
+}
+
+// selectMediaType returns the name and definition of the first JSON media type in a content map, or the first of any type if there is none
+func selectMediaType(content map[string]interface{}) (string, map[string]interface{}) {
+	names := make([]string, 0, len(content))
+	for name := range content {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if isJSONMediaType(name) {
+			return name, asMap(content[name])
+		}
+	}
+	if len(names) > 0 {
+		return names[0], asMap(content[names[0]])
+	}
+	return "", nil
+}
+
+// isJSONMediaType returns whether a media type holds JSON, such as application/json or application/problem+json
+func isJSONMediaType(mediaType string) bool {
+	mediaType = strings.ToLower(strings.TrimSpace(strings.Split(mediaType, ";")[0]))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// stringKeys converts every map with interface keys within a decoded YAML document to a map with string keys
+func stringKeys(input interface{}) interface{} {
+	switch value := input.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			result[fmt.Sprint(k)] = stringKeys(v)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, v := range value {
+			result[i] = stringKeys(v)
+		}
+		return result
+	default:
+		return input
+	}
+}
+
+// asMap returns the value as a map with string keys, or nil if it is not one
+func asMap(value interface{}) map[string]interface{} {
+	result, _ := value.(map[string]interface{})
+	return result
+}
+
+// asSlice returns the value as a slice, or nil if it is not one
+func asSlice(value interface{}) []interface{} {
+	result, _ := value.([]interface{})
+	return result
+}
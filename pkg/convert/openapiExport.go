@@ -0,0 +1,426 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// openAPITypes maps ministub types to OpenAPI schema types
+var openAPITypes = map[string]string{
+	"string":  "string",
+	"integer": "integer",
+	"float":   "number",
+	"boolean": "boolean",
+	"array":   "array",
+	"object":  "object",
+}
+
+// ignoredHeaderParams are headers OpenAPI does not allow to be described as parameters
+var ignoredHeaderParams = map[string]bool{
+	"accept":        true,
+	"content-type":  true,
+	"authorization": true,
+}
+
+/*ToOpenAPI generates an OpenAPI 3.1 document describing the endpoints of the given config, with the given title and API
+version. Operations are visited in order so the suffixes added to duplicate operation IDs are stable */
+func ToOpenAPI(cfg *config.Config, title, version string) map[string]interface{} {
+	paths := make(map[string]interface{}, len(cfg.Endpoints))
+	usedIDs := make(map[string]bool)
+
+	urls := make([]string, 0, len(cfg.Endpoints))
+	for url := range cfg.Endpoints {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	for _, url := range urls {
+		methods := cfg.Endpoints[url]
+		names := make([]string, 0, len(methods))
+		for method := range methods {
+			names = append(names, method)
+		}
+		sort.Strings(names)
+
+		item := make(map[string]interface{}, len(methods))
+		for _, method := range names {
+			if methods[method] == nil {
+				continue
+			}
+			// paths such as '/a-b' and '/a/b' generate the same ID, so later ones are numbered from 2
+			base := operationID(url, method)
+			id := base
+			for suffix := 2; usedIDs[id]; suffix++ {
+				id = fmt.Sprintf("%s%d", base, suffix)
+			}
+			usedIDs[id] = true
+			item[strings.ToLower(method)] = exportOperation(url, method, id, methods[method])
+		}
+		paths[openAPIPath(url)] = item
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+	}
+}
+
+// openAPIPath converts ':name' path segments into '{name}' templates
+func openAPIPath(url string) string {
+	segments := strings.Split(url, "/")
+	for i, segment := range segments {
+		if len(segment) > 1 && segment[0] == ':' {
+			segments[i] = fmt.Sprintf("{%s}", segment[1:])
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// exportOperation generates an OpenAPI operation for a single endpoint method, entries left empty in the config are skipped
+func exportOperation(url, method, id string, entry *config.Endpoint) map[string]interface{} {
+	operation := map[string]interface{}{
+		"operationId": id,
+	}
+
+	parameters := make([]interface{}, 0)
+	for _, segment := range strings.Split(url, "/") {
+		if len(segment) < 2 || segment[0] != ':' {
+			continue
+		}
+		paramType := "string"
+		if entry.Params != nil && entry.Params.Path[segment[1:]] != nil {
+			paramType = entry.Params.Path[segment[1:]].Type
+		}
+		parameters = append(parameters, map[string]interface{}{
+			"name":     segment[1:],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": openAPITypes[paramType]},
+		})
+	}
+
+	if entry.Params != nil {
+		for _, name := range sortedKeys(entry.Params.Query) {
+			if entry.Params.Query[name] == nil {
+				continue
+			}
+			parameters = append(parameters, map[string]interface{}{
+				"name":     name,
+				"in":       "query",
+				"required": entry.Params.Query[name].Required,
+				"schema":   map[string]interface{}{"type": openAPITypes[entry.Params.Query[name].Type]},
+			})
+		}
+	}
+
+	if entry.Recieves != nil {
+		headerNames := make([]string, 0, len(entry.Recieves.Headers))
+		for name := range entry.Recieves.Headers {
+			// OpenAPI ignores header parameters which are described elsewhere in the operation
+			if !ignoredHeaderParams[strings.ToLower(name)] {
+				headerNames = append(headerNames, name)
+			}
+		}
+		sort.Strings(headerNames)
+		for _, name := range headerNames {
+			parameters = append(parameters, map[string]interface{}{
+				"name":     name,
+				"in":       "header",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string", "const": entry.Recieves.Headers[name]},
+			})
+		}
+
+		if len(entry.Recieves.Body) > 0 {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": bodySchema(entry.Recieves.Body)},
+				},
+			}
+		} else if len(entry.Recieves.XPath) > 0 {
+			operation["requestBody"] = xmlRequestBody(entry.Recieves.XPath)
+		}
+	}
+
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	responses := exportResponses(entry, "")
+
+	// SOAP operations share a single path and method, so their examples are merged and keyed by operation
+	if len(entry.SOAP) > 0 {
+		names := make([]string, 0, len(entry.SOAP))
+		for name := range entry.SOAP {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		xpaths := make(map[string]string)
+		for _, name := range names {
+			opEntry := entry.SOAP[name]
+			if opEntry == nil {
+				continue
+			}
+			for code, resp := range exportResponses(opEntry, "text/xml") {
+				responses[code] = mergeSOAPResponse(responses[code], resp.(map[string]interface{}), name)
+			}
+			if opEntry.Recieves != nil {
+				for path, exType := range opEntry.Recieves.XPath {
+					xpaths[path] = exType
+				}
+			}
+		}
+		operation["x-soap-operations"] = names
+		operation["requestBody"] = xmlRequestBody(xpaths)
+	}
+
+	operation["responses"] = responses
+	return operation
+}
+
+// exportResponses generates the OpenAPI responses for an endpoint keyed by status code, bodies without
+// a Content-Type header are described with defaultType when given
+func exportResponses(entry *config.Endpoint, defaultType string) map[string]interface{} {
+	responses := make(map[string]interface{})
+
+	if entry.Response > 0 {
+		responses[strconv.Itoa(entry.Response)] = map[string]interface{}{
+			"description": http.StatusText(entry.Response),
+		}
+	}
+
+	for statusCode, resp := range entry.Responses {
+		if resp == nil {
+			continue
+		}
+		result := map[string]interface{}{
+			"description": fmt.Sprintf("%s (weight %d)", http.StatusText(statusCode), resp.Weight),
+		}
+
+		contentType := ""
+		headers := make(map[string]interface{})
+		for name, value := range resp.Headers {
+			if strings.ToLower(name) == "content-type" {
+				contentType = value
+				continue
+			}
+			headers[name] = map[string]interface{}{
+				"schema": map[string]interface{}{"type": "string", "example": value},
+			}
+		}
+		if len(headers) > 0 {
+			result["headers"] = headers
+		}
+
+		var example interface{}
+		switch {
+		case resp.Fault != nil:
+			contentType, example = "text/xml", faultExample(resp.Fault)
+		case len(resp.RawBody) > 0:
+			example = resp.RawBody
+		case len(resp.BodyFile) > 0:
+			example = nil
+		case resp.Body != nil:
+			example = stringKeys(resp.Body)
+		}
+
+		if example != nil || len(resp.BodyFile) > 0 {
+			if len(contentType) == 0 && len(defaultType) > 0 {
+				contentType = defaultType
+			} else if len(contentType) == 0 {
+				contentType = "application/json"
+				if _, isString := example.(string); isString || example == nil {
+					contentType = "text/plain"
+				}
+			}
+			media := make(map[string]interface{})
+			if example != nil {
+				media["example"] = example
+			}
+			result["content"] = map[string]interface{}{contentType: media}
+		}
+
+		responses[strconv.Itoa(statusCode)] = result
+	}
+
+	return responses
+}
+
+// mergeSOAPResponse merges the response of a single SOAP operation into the response shared by every operation
+func mergeSOAPResponse(dst interface{}, src map[string]interface{}, operation string) map[string]interface{} {
+	merged, found := dst.(map[string]interface{})
+	if !found {
+		merged = map[string]interface{}{"description": src["description"]}
+	}
+	for name, header := range asMap(src["headers"]) {
+		headers, found := merged["headers"].(map[string]interface{})
+		if !found {
+			headers = make(map[string]interface{})
+			merged["headers"] = headers
+		}
+		headers[name] = header
+	}
+
+	for contentType, media := range asMap(src["content"]) {
+		content, found := merged["content"].(map[string]interface{})
+		if !found {
+			content = make(map[string]interface{})
+			merged["content"] = content
+		}
+		mergedMedia, found := content[contentType].(map[string]interface{})
+		if !found {
+			mergedMedia = make(map[string]interface{})
+			content[contentType] = mergedMedia
+		}
+		if example, found := asMap(media)["example"]; found {
+			examples, found := mergedMedia["examples"].(map[string]interface{})
+			if !found {
+				examples = make(map[string]interface{})
+				mergedMedia["examples"] = examples
+			}
+			examples[operation] = map[string]interface{}{"value": example}
+		}
+	}
+
+	return merged
+}
+
+// bodySchema generates an object schema from 'recieves.body' dotted paths, numeric segments become array items
+func bodySchema(fields map[string]string) map[string]interface{} {
+	root := map[string]interface{}{"type": "object"}
+
+	for _, path := range sortedStringKeys(fields) {
+		current := root
+		segments := strings.Split(path, ".")
+
+		for i, segment := range segments {
+			last := i == len(segments)-1
+
+			if _, err := strconv.Atoi(segment); err == nil {
+				current["type"] = "array"
+				items, found := current["items"].(map[string]interface{})
+				if !found {
+					items = map[string]interface{}{}
+					current["items"] = items
+				}
+				if last {
+					items["type"] = openAPITypes[fields[path]]
+				} else if _, found := items["type"]; !found {
+					items["type"] = "object"
+				}
+				current = items
+				continue
+			}
+
+			properties, found := current["properties"].(map[string]interface{})
+			if !found {
+				properties = make(map[string]interface{})
+				current["properties"] = properties
+			}
+			required, _ := current["required"].([]interface{})
+			if !containsValue(required, segment) {
+				current["required"] = append(required, segment)
+			}
+
+			child, found := properties[segment].(map[string]interface{})
+			if !found {
+				child = map[string]interface{}{"type": "object"}
+				properties[segment] = child
+			}
+			if last {
+				child["type"] = openAPITypes[fields[path]]
+			}
+			current = child
+		}
+	}
+
+	return root
+}
+
+// xmlRequestBody generates a request body describing the XPath checks made on an XML body
+func xmlRequestBody(xpaths map[string]string) map[string]interface{} {
+	media := map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}
+	if len(xpaths) > 0 {
+		media["x-xpath"] = xpaths
+	}
+	return map[string]interface{}{
+		"required": true,
+		"content":  map[string]interface{}{"text/xml": media},
+	}
+}
+
+// faultExample generates an example SOAP fault envelope, escaped and with its detail as the fault is served
+func faultExample(fault *config.Fault) string {
+	buf := new(bytes.Buffer)
+	buf.WriteString(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>`)
+	xml.EscapeText(buf, []byte(fault.Code))
+	buf.WriteString("</faultcode><faultstring>")
+	xml.EscapeText(buf, []byte(fault.String))
+	buf.WriteString("</faultstring>")
+	if len(fault.Detail) > 0 {
+		buf.WriteString(fmt.Sprintf("<detail>%s</detail>", fault.Detail))
+	}
+	buf.WriteString("</soap:Fault></soap:Body></soap:Envelope>")
+	return buf.String()
+}
+
+// operationID generates a unique operation ID from a url and method, such as 'postApiV1JobByJobId'
+func operationID(url, method string) string {
+	result := strings.ToLower(method)
+	for _, segment := range strings.Split(url, "/") {
+		prefix := ""
+		if strings.HasPrefix(segment, ":") {
+			prefix, segment = "By", segment[1:]
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9')
+		}) {
+			result += prefix + strings.ToUpper(word[:1]) + word[1:]
+			prefix = ""
+		}
+	}
+	return result
+}
+
+// sortedKeys returns the names of the given params in order
+func sortedKeys(params map[string]*config.ParamEntry) []string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedStringKeys returns the keys of the given map in order
+func sortedStringKeys(input map[string]string) []string {
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// containsValue returns whether the slice holds the given value
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, entry := range values {
+		if entry == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package convert
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// TestToOpenAPI1 ensures path params, query params, request schemas and response examples are exported
+func TestToOpenAPI1(t *testing.T) {
+	cfg := &config.Config{
+		Version: 1.0,
+		Endpoints: map[string]map[string]*config.Endpoint{
+			"/api/v1/job/:jobId": {
+				"post": {
+					Params: &config.Parameters{
+						Path:  map[string]*config.ParamEntry{"jobId": {Type: "integer"}},
+						Query: map[string]*config.ParamEntry{"verbose": {Type: "boolean", Required: false}},
+					},
+					Recieves: &config.Recieves{
+						Headers: map[string]string{"Content-Type": "application/json", "X-Api-Key": "secret"},
+						Body:    map[string]string{"name": "string", "owner.id": "integer", "tags.0": "string"},
+					},
+					Responses: map[int]*config.Response{
+						201: {Weight: 100, Body: map[string]interface{}{"status": "created"}},
+					},
+				},
+			},
+		},
+	}
+
+	spec := ToOpenAPI(cfg, "jobs", "1.0.0")
+	if spec["openapi"] != "3.1.0" {
+		t.Errorf("Incorrect OpenAPI Version: %v", spec["openapi"])
+	}
+
+	item := asMap(asMap(spec["paths"])["/api/v1/job/{jobId}"])
+	operation := asMap(item["post"])
+	if operation == nil {
+		t.Fatalf("Templated Path Not Exported")
+	}
+	if operation["operationId"] != "postApiV1JobByJobId" {
+		t.Errorf("Incorrect Operation ID: %v", operation["operationId"])
+	}
+
+	parameters := asSlice(operation["parameters"])
+	if len(parameters) != 3 {
+		t.Fatalf("Expected 3 Parameters, Got %d", len(parameters))
+	}
+	path := asMap(parameters[0])
+	if path["in"] != "path" || path["name"] != "jobId" || asMap(path["schema"])["type"] != "integer" {
+		t.Errorf("Incorrect Path Param: %v", path)
+	}
+	query := asMap(parameters[1])
+	if query["in"] != "query" || query["required"] != false || asMap(query["schema"])["type"] != "boolean" {
+		t.Errorf("Incorrect Query Param: %v", query)
+	}
+	if header := asMap(parameters[2]); header["in"] != "header" || asMap(header["schema"])["const"] != "secret" {
+		t.Errorf("Incorrect Header Param: %v", header)
+	}
+
+	schema := asMap(asMap(asMap(asMap(operation["requestBody"])["content"])["application/json"])["schema"])
+	properties := asMap(schema["properties"])
+	if len(asSlice(schema["required"])) != 3 {
+		t.Errorf("Expected 3 Required Fields, Got %v", schema["required"])
+	}
+	if asMap(asMap(asMap(properties["owner"])["properties"])["id"])["type"] != "integer" {
+		t.Errorf("Nested Field Not Exported: %v", properties["owner"])
+	}
+	if tags := asMap(properties["tags"]); tags["type"] != "array" || asMap(tags["items"])["type"] != "string" {
+		t.Errorf("Array Field Not Exported: %v", tags)
+	}
+
+	created := asMap(asMap(operation["responses"])["201"])
+	example := asMap(asMap(asMap(created["content"])["application/json"])["example"])
+	if example["status"] != "created" {
+		t.Errorf("Response Example Not Exported: %v", created)
+	}
+
+	if _, err := json.Marshal(spec); err != nil {
+		t.Errorf("Spec Cannot Be Marshalled To JSON: %s", err.Error())
+	}
+}
+
+// TestToOpenAPI2 ensures SOAP operation examples sharing a status code are all kept
+func TestToOpenAPI2(t *testing.T) {
+	cfg := &config.Config{
+		Version: 1.0,
+		Endpoints: map[string]map[string]*config.Endpoint{
+			"/payments": {
+				"post": {
+					SOAP: map[string]*config.Endpoint{
+						"Authorise": {Responses: map[int]*config.Response{200: {Weight: 100, RawBody: "<Authorised/>"}}},
+						"Refund":    {Responses: map[int]*config.Response{200: {Weight: 100, RawBody: "<Refunded/>"}}},
+					},
+				},
+			},
+		},
+	}
+
+	operation := asMap(asMap(asMap(ToOpenAPI(cfg, "payments", "1.0.0")["paths"])["/payments"])["post"])
+	ok := asMap(asMap(operation["responses"])["200"])
+	examples := asMap(asMap(asMap(ok["content"])["text/xml"])["examples"])
+
+	if len(examples) != 2 || asMap(examples["Refund"])["value"] != "<Refunded/>" {
+		t.Errorf("SOAP Examples Not Merged: %v", examples)
+	}
+	if names, _ := operation["x-soap-operations"].([]string); len(names) != 2 {
+		t.Errorf("Expected 2 SOAP Operations, Got %v", operation["x-soap-operations"])
+	}
+}
+
+// TestToOpenAPI3 ensures duplicate operation IDs are numbered, empty entries are skipped and faults are escaped
+func TestToOpenAPI3(t *testing.T) {
+	cfg := &config.Config{
+		Version: 1.0,
+		Endpoints: map[string]map[string]*config.Endpoint{
+			"/a-b": {"get": {Response: 200}},
+			"/a/b": {"get": {Response: 200}},
+			"/search": {
+				"get": {
+					Params: &config.Parameters{Query: map[string]*config.ParamEntry{"q": nil}},
+					Responses: map[int]*config.Response{
+						500: {Weight: 100, Fault: &config.Fault{Code: "soap:Server", String: "Limit < 10 & Over"}},
+					},
+				},
+			},
+		},
+	}
+
+	spec := ToOpenAPI(cfg, "api", "2.3.0")
+	if asMap(spec["info"])["version"] != "2.3.0" {
+		t.Errorf("API Version Not Exported: %v", spec["info"])
+	}
+
+	paths := asMap(spec["paths"])
+	first, second := asMap(asMap(paths["/a-b"])["get"]), asMap(asMap(paths["/a/b"])["get"])
+	if first["operationId"] != "getAB" || second["operationId"] != "getAB2" {
+		t.Errorf("Duplicate Operation IDs Not Numbered: %v, %v", first["operationId"], second["operationId"])
+	}
+
+	search := asMap(asMap(paths["/search"])["get"])
+	if _, found := search["parameters"]; found {
+		t.Errorf("Empty Query Param Was Exported: %v", search["parameters"])
+	}
+	fault := asMap(asMap(asMap(asMap(search["responses"])["500"])["content"])["text/xml"])["example"]
+	if fault != `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Server</faultcode><faultstring>Limit &lt; 10 &amp; Over</faultstring></soap:Fault></soap:Body></soap:Envelope>` {
+		t.Errorf("Fault Example Not Escaped: %v", fault)
+	}
+}
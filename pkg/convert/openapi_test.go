@@ -0,0 +1,154 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// testOpenAPISpec uses path item params, refs, named examples, schema examples and nested required fields
+const testOpenAPISpec = `
+openapi: 3.0.3
+info: {title: Pets, version: "1"}
+servers:
+  - url: "http://pets.example.com/{base}/"
+    variables: {base: {default: api}}
+paths:
+  /pets/{petId}:
+    parameters:
+      - {name: petId, in: path, required: true, schema: {type: integer}}
+    get:
+      parameters:
+        - {name: verbose, in: query, schema: {type: boolean}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+        "404":
+          description: missing
+          content:
+            application/problem+json:
+              examples:
+                missing: {value: {title: Not Found}}
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name, owner]
+              properties:
+                name: {type: string}
+                age: {type: integer}
+                owner: {type: object, required: [id], properties: {id: {type: integer}}}
+      responses:
+        "201": {description: created}
+        default: {description: error}
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        id: {type: integer, example: 1}
+        name: {type: string, example: Rex}
+`
+
+// TestFromOpenAPI1 ensures operations are converted into endpoints with params, body fields and weighted responses
+func TestFromOpenAPI1(t *testing.T) {
+	cfg, err := FromOpenAPI([]byte(testOpenAPISpec))
+	if err != nil {
+		t.Fatalf("Error Encountered Importing OpenAPI Spec: %s", err.Error())
+	}
+
+	get := cfg.Endpoints["/api/pets/:petId"]["get"]
+	if get == nil {
+		t.Fatalf("Endpoint GET /api/pets/:petId Not Generated Under The Server Path: %v", cfg.Endpoints)
+	}
+	if get.Params.Path["petId"].Type != "integer" || get.Params.Query["verbose"].Type != "boolean" {
+		t.Errorf("Params Not Generated From Path Item And Operation")
+	}
+	if len(get.Responses) != 2 || get.Responses[200].Weight != 90 || get.Responses[404].Weight != 10 {
+		t.Errorf("2xx Response Not Weighted 90: %v", get.Responses)
+	}
+	if get.Responses[200].Body["name"] != "Rex" {
+		t.Errorf("Schema Example Not Used As Body: %v", get.Responses[200].Body)
+	}
+	if get.Responses[404].Body["title"] != "Not Found" {
+		t.Errorf("Named Example Not Used As Body: %v", get.Responses[404].Body)
+	}
+
+	post := cfg.Endpoints["/api/pets"]["post"]
+	if post == nil || post.Recieves == nil {
+		t.Fatalf("Endpoint POST /api/pets Not Generated With Recieves")
+	}
+	if len(post.Recieves.Body) != 2 || post.Recieves.Body["name"] != "string" || post.Recieves.Body["owner.id"] != "integer" {
+		t.Errorf("Required Body Fields Not Generated: %v", post.Recieves.Body)
+	}
+	if len(post.Responses) != 1 {
+		t.Errorf("Default Response Was Not Skipped")
+	}
+}
+
+// TestFromOpenAPI2 ensures documents which are not OpenAPI 3 specs are returned as errors
+func TestFromOpenAPI2(t *testing.T) {
+	for _, data := range []string{"", "swagger: '2.0'", "openapi: 3.0.0\npaths: {}", "version: 1.0"} {
+		if _, err := FromOpenAPI([]byte(data)); err == nil {
+			t.Errorf("Invalid Spec '%s' Returned No Error", data)
+		}
+		if IsOpenAPI([]byte(data)) && data != "openapi: 3.0.0\npaths: {}" {
+			t.Errorf("Document '%s' Detected As OpenAPI", data)
+		}
+	}
+}
+
+// TestFromOpenAPI3 ensures an operation with no 2xx response mostly returns its lowest, and the config passes lint
+func TestFromOpenAPI3(t *testing.T) {
+	spec := `
+openapi: 3.0.3
+info: {title: Pets, version: "1"}
+servers: [{url: "http://pets.example.com"}]
+paths:
+  /pets/{petId}:
+    delete:
+      parameters: [{name: petId, in: path, required: true, schema: {type: integer}}]
+      responses:
+        "409": {description: conflict}
+        "404":
+          description: missing
+          content:
+            application/problem+json:
+              examples:
+                missing: {value: {title: Not Found}}
+`
+	cfg, err := FromOpenAPI([]byte(spec))
+	if err != nil {
+		t.Fatalf("Error Encountered Importing OpenAPI Spec: %s", err.Error())
+	}
+
+	entry := cfg.Endpoints["/pets/:petId"]["delete"]
+	if entry == nil || len(entry.Responses) != 2 || entry.Responses[404].Weight != 90 || entry.Responses[409].Weight != 10 {
+		t.Fatalf("Lowest Response Not Weighted 90: %v", cfg.Endpoints)
+	}
+	if entry.Responses[404].Body["title"] != "Not Found" {
+		t.Errorf("Named Example Not Used As Body: %v", entry.Responses[404].Body)
+	}
+
+	if err := config.Lint(cfg); err != nil {
+		t.Errorf("Imported Config Failed Lint: %s", err.Error())
+	}
+	if err := config.Lint(mustFromOpenAPI(t, testOpenAPISpec)); err != nil {
+		t.Errorf("Imported Config Failed Lint: %s", err.Error())
+	}
+}
+
+// mustFromOpenAPI imports the given spec, failing the test on error
+func mustFromOpenAPI(t *testing.T, spec string) *config.Config {
+	cfg, err := FromOpenAPI([]byte(spec))
+	if err != nil {
+		t.Fatalf("Error Encountered Importing OpenAPI Spec: %s", err.Error())
+	}
+	return cfg
+}
@@ -0,0 +1,4 @@
+/*
+Package convert generates ministub configs from captured traffic and other API definition formats
+*/
+package convert
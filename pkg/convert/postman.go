@@ -0,0 +1,449 @@
+package convert
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/MichaelWittgreffe/ministub/pkg/config"
+)
+
+// postmanVariable matches a '{{name}}' collection variable reference
+var postmanVariable = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// postmanCollection represents the parts of a Postman v2.1 collection used for importing
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item     []*postmanItem    `json:"item"`
+	Auth     *postmanAuth      `json:"auth"`
+	Variable []*postmanKeyPair `json:"variable"`
+}
+
+// postmanItem represents either a folder of items or a single request within a collection
+type postmanItem struct {
+	Name     string          `json:"name"`
+	Item     []*postmanItem  `json:"item"`
+	Auth     *postmanAuth    `json:"auth"`
+	Request  json.RawMessage `json:"request"`
+	Response []struct {
+		Code int `json:"code"`
+	} `json:"response"`
+}
+
+// postmanRequest represents a request within a collection, the URL is either a string or an object
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Header []*postmanKeyPair `json:"header"`
+	URL    json.RawMessage   `json:"url"`
+	Auth   *postmanAuth      `json:"auth"`
+	Body   *struct {
+		Mode string `json:"mode"`
+		Raw  string `json:"raw"`
+	} `json:"body"`
+}
+
+// postmanURL represents the object form of a request URL
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Variable []*postmanKeyPair `json:"variable"`
+}
+
+// postmanAuth represents the auth settings of a collection, folder or request
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []*postmanKeyPair `json:"bearer"`
+	Basic  []*postmanKeyPair `json:"basic"`
+	APIKey []*postmanKeyPair `json:"apikey"`
+}
+
+// postmanKeyPair represents a variable, header or auth setting within a collection
+type postmanKeyPair struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Disabled bool        `json:"disabled"`
+}
+
+// postmanImporter holds the state of a single collection import
+type postmanImporter struct {
+	variables map[string]string
+	cfg       *config.Config
+	warnings  []string
+}
+
+/*FromPostman generates a v1 config with a request for every request in the given Postman v2.1 collection and a service
+for every base URL, collection variables become '${VAR}' env references. A config must serve an endpoint, so a 'GET /'
+endpoint returning 200 is added for the config to load on its own. Requests which are skipped and parts of requests which
+are changed or left out are described in the returned warnings */
+func FromPostman(data []byte) (*config.Config, []string, error) {
+	collection := new(postmanCollection)
+	if err := json.Unmarshal(data, collection); err != nil {
+		return nil, nil, fmt.Errorf("Unable To Unmarshal Collection: %s", err.Error())
+	}
+	if !strings.Contains(collection.Info.Schema, "/v2.1") {
+		return nil, nil, fmt.Errorf("Unsupported Collection Schema: %s", collection.Info.Schema)
+	}
+
+	imp := &postmanImporter{
+		variables: make(map[string]string, len(collection.Variable)),
+		cfg: &config.Config{
+			Version:  1.0,
+			Services: make(map[string]*config.Service),
+			Requests: make(map[string]*config.Request),
+		},
+		warnings: make([]string, 0),
+	}
+	for _, variable := range collection.Variable {
+		imp.variables[variable.Key] = keyPairValue(variable)
+	}
+
+	if err := imp.importItems(collection.Item, nil, collection.Auth); err != nil {
+		return nil, nil, err
+	}
+	if len(imp.cfg.Requests) == 0 {
+		return nil, nil, fmt.Errorf("No Requests To Import")
+	}
+
+	imp.cfg.Endpoints = map[string]map[string]*config.Endpoint{"/": {"get": {Response: 200}}}
+	if err := config.Validate(imp.cfg); err != nil {
+		return nil, nil, fmt.Errorf("Generated Config Is Invalid: %s", err.Error())
+	}
+	return imp.cfg, imp.warnings, nil
+}
+
+// importItems imports every request within the given items, folders are imported recursively and pass on their auth
+func (imp *postmanImporter) importItems(items []*postmanItem, folders []string, auth *postmanAuth) error {
+	for _, item := range items {
+		itemAuth := auth
+		if item.Auth != nil {
+			itemAuth = item.Auth
+		}
+
+		if len(item.Request) == 0 {
+			if err := imp.importItems(item.Item, append(folders, item.Name), itemAuth); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := uniqueName(requestName(append(folders, item.Name)), imp.cfg.Requests)
+		if err := imp.importRequest(name, item, itemAuth); err != nil {
+			return fmt.Errorf("Invalid Request %s: %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+// importRequest converts a single collection request into a request and the service for its base URL
+func (imp *postmanImporter) importRequest(name string, item *postmanItem, auth *postmanAuth) error {
+	req := new(postmanRequest)
+	var rawURL string
+	if err := json.Unmarshal(item.Request, &rawURL); err == nil {
+		req.URL, _ = json.Marshal(rawURL)
+	} else if err := json.Unmarshal(item.Request, req); err != nil {
+		return fmt.Errorf("Unable To Unmarshal Request: %s", err.Error())
+	}
+	if req.Auth != nil {
+		auth = req.Auth
+	}
+
+	method := strings.ToLower(req.Method)
+	if len(method) == 0 {
+		method = "get"
+	}
+	switch {
+	case method == "get", method == "post", method == "put", method == "delete":
+	default:
+		imp.warnings = append(imp.warnings, fmt.Sprintf("%s: Method %s Not Supported, Request Skipped", name, req.Method))
+		return nil
+	}
+
+	reqURL := new(postmanURL)
+	if err := json.Unmarshal(req.URL, &reqURL.Raw); err != nil {
+		if err := json.Unmarshal(req.URL, reqURL); err != nil {
+			return fmt.Errorf("Unable To Unmarshal URL: %s", err.Error())
+		}
+	}
+
+	serviceName, service, protocol, path, err := imp.splitURL(reqURL)
+	if err != nil {
+		return err
+	}
+
+	result := &config.Request{
+		URL:              path,
+		Method:           method,
+		Protocol:         protocol,
+		Headers:          make(map[string]string),
+		ExpectedResponse: &config.Response{StatusCode: 200},
+	}
+	if len(item.Response) > 0 && item.Response[0].Code > 0 {
+		result.ExpectedResponse.StatusCode = item.Response[0].Code
+	}
+
+	for _, header := range req.Header {
+		if !header.Disabled {
+			result.Headers[header.Key] = envRefs(keyPairValue(header))
+		}
+	}
+	imp.applyAuth(result, auth)
+
+	if req.Body != nil && len(req.Body.Raw) > 0 {
+		body := make(map[string]interface{})
+		raw, quoted := quoteBareVariables(req.Body.Raw)
+		switch {
+		case req.Body.Mode != "raw":
+			imp.warnings = append(imp.warnings, fmt.Sprintf("%s: Body Mode %s Not Supported, Body Omitted", name, req.Body.Mode))
+		case json.Unmarshal([]byte(envRefs(raw)), &body) != nil:
+			imp.warnings = append(imp.warnings, fmt.Sprintf("%s: Body Is Not A JSON Object, Body Omitted", name))
+		default:
+			result.Body = body
+			if quoted {
+				imp.warnings = append(imp.warnings, fmt.Sprintf("%s: Body Variables Outside Of Strings Are Typed From Their Value When Loaded", name))
+			}
+		}
+	}
+
+	if len(result.Headers) == 0 {
+		result.Headers = nil
+	}
+
+	if existing, found := imp.cfg.Services[serviceName]; found && *existing != *service {
+		serviceName = fmt.Sprintf("%s_%d", serviceName, service.Port)
+	}
+	imp.cfg.Services[serviceName] = service
+	imp.cfg.Requests[name] = result
+	return nil
+}
+
+/*splitURL splits a request URL into the service it is sent to and the protocol and path of the request. A URL starting
+with a variable holding a full base URL, such as '{{baseUrl}}/users', uses the variable's value for the service as a
+hostname and port cannot be read from a single env var */
+func (imp *postmanImporter) splitURL(reqURL *postmanURL) (string, *config.Service, string, string, error) {
+	raw := strings.TrimSpace(reqURL.Raw)
+	protocol, host, path := "http", raw, ""
+
+	// path variables such as ':id' are given values on the URL object
+	pathVars := make(map[string]string, len(reqURL.Variable))
+	for _, variable := range reqURL.Variable {
+		pathVars[variable.Key] = keyPairValue(variable)
+	}
+
+	if match := postmanVariable.FindStringSubmatchIndex(raw); match != nil && match[0] == 0 {
+		name := raw[match[2]:match[3]]
+		if base, err := url.Parse(imp.variables[name]); err == nil && len(base.Scheme) > 0 && len(base.Host) > 0 {
+			service, err := serviceFor(base.Scheme, base.Hostname(), base.Port())
+			if err != nil {
+				return "", nil, "", "", err
+			}
+			path := strings.TrimSuffix(base.Path, "/") + raw[match[1]:]
+			return name, service, base.Scheme, requestPath(path, pathVars), nil
+		}
+	}
+
+	if i := strings.Index(raw, "://"); i >= 0 {
+		protocol, host = strings.ToLower(raw[:i]), raw[i+3:]
+	}
+	if i := strings.IndexAny(host, "/?"); i >= 0 {
+		host, path = host[:i], host[i:]
+	}
+	if len(host) == 0 {
+		return "", nil, "", "", fmt.Errorf("URL %s Has No Host", raw)
+	}
+
+	hostname, port := host, ""
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.HasSuffix(host, "}}") {
+		hostname, port = host[:i], host[i+1:]
+	}
+	// a port variable is resolved from the collection as a service port must be an integer
+	if match := postmanVariable.FindStringSubmatch(port); match != nil {
+		port = imp.variables[match[1]]
+	}
+
+	service, err := serviceFor(protocol, envRefs(hostname), port)
+	if err != nil {
+		return "", nil, "", "", err
+	}
+	name := strings.NewReplacer("{{", "", "}}", "").Replace(hostname)
+	return name, service, protocol, requestPath(path, pathVars), nil
+}
+
+// applyAuth adds the header or query param required by the given auth settings to the request
+func (imp *postmanImporter) applyAuth(req *config.Request, auth *postmanAuth) {
+	if auth == nil {
+		return
+	}
+
+	settings := make(map[string]string)
+	for _, pairs := range [][]*postmanKeyPair{auth.Bearer, auth.Basic, auth.APIKey} {
+		for _, pair := range pairs {
+			settings[pair.Key] = keyPairValue(pair)
+		}
+	}
+
+	switch {
+	case auth.Type == "bearer":
+		req.Headers["Authorization"] = "Bearer " + envRefs(settings["token"])
+	case auth.Type == "basic":
+		credentials := settings["username"] + ":" + settings["password"]
+		// credentials held in variables cannot be encoded ahead of time, so are read pre-encoded from BASIC_AUTH
+		if postmanVariable.MatchString(credentials) {
+			req.Headers["Authorization"] = "Basic ${BASIC_AUTH}"
+		} else {
+			req.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+		}
+	case auth.Type == "apikey":
+		key, value := envRefs(settings["key"]), envRefs(settings["value"])
+		if settings["in"] == "query" {
+			separator := "?"
+			if strings.Contains(req.URL, "?") {
+				separator = "&"
+			}
+			req.URL += separator + key + "=" + value
+		} else {
+			req.Headers[key] = value
+		}
+	}
+}
+
+// serviceFor creates a service for the given hostname, defaulting the port from the protocol
+func serviceFor(protocol, hostname, port string) (*config.Service, error) {
+	service := &config.Service{Hostname: hostname, Port: 80}
+	if protocol == "https" {
+		service.Port = 443
+	}
+
+	if len(port) > 0 {
+		var err error
+		if service.Port, err = strconv.Atoi(port); err != nil {
+			return nil, fmt.Errorf("Invalid Port %s For Host %s", port, hostname)
+		}
+	}
+	return service, nil
+}
+
+// requestPath converts variables in a request path into env references, giving ':name' path variables their values
+func requestPath(path string, pathVars map[string]string) string {
+	query := ""
+	if i := strings.Index(path, "?"); i >= 0 {
+		path, query = path[:i], path[i:]
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if value, found := pathVars[strings.TrimPrefix(segment, ":")]; found && strings.HasPrefix(segment, ":") && len(value) > 0 {
+			segments[i] = value
+		}
+	}
+
+	path = strings.Join(segments, "/")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return envRefs(path + query)
+}
+
+/*quoteBareVariables quotes '{{name}}' variables used as JSON values outside of a string, such as '{"count": {{count}}}',
+so the body can be parsed. It returns whether any variable was quoted */
+func quoteBareVariables(raw string) (string, bool) {
+	var result strings.Builder
+	inString, escaped, quoted := false, false, false
+
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case inString && escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case !inString && strings.HasPrefix(raw[i:], "{{"):
+			if end := strings.Index(raw[i:], "}}"); end > 0 {
+				result.WriteString(`"` + raw[i:i+end+2] + `"`)
+				i += end + 1
+				quoted = true
+				continue
+			}
+		}
+		result.WriteByte(c)
+	}
+	return result.String(), quoted
+}
+
+// envRefs replaces '{{name}}' collection variables with '${NAME}' env references, dynamic variables such as '{{$guid}}' are kept
+func envRefs(value string) string {
+	return postmanVariable.ReplaceAllStringFunc(value, func(ref string) string {
+		name := ref[2 : len(ref)-2]
+		if strings.HasPrefix(name, "$") {
+			return ref
+		}
+		return fmt.Sprintf("${%s}", envName(name))
+	})
+}
+
+// envName converts a variable name into an environment variable name, such as 'baseUrl' to 'BASE_URL'
+func envName(name string) string {
+	var result strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			if i > 0 && ((name[i-1] >= 'a' && name[i-1] <= 'z') || (name[i-1] >= '0' && name[i-1] <= '9')) {
+				result.WriteRune('_')
+			}
+			result.WriteRune(r)
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			result.WriteRune(r)
+		default:
+			result.WriteRune('_')
+		}
+	}
+	return strings.ToUpper(result.String())
+}
+
+// requestName generates a request ID from the folder and request names, such as 'usersCreateUser'
+func requestName(names []string) string {
+	var result strings.Builder
+	for _, name := range names {
+		for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9')
+		}) {
+			if result.Len() == 0 {
+				result.WriteString(strings.ToLower(word[:1]) + word[1:])
+			} else {
+				result.WriteString(strings.ToUpper(word[:1]) + word[1:])
+			}
+		}
+	}
+	if result.Len() == 0 {
+		return "request"
+	}
+	return result.String()
+}
+
+// uniqueName returns the name, suffixed with a number if it is already used by a request
+func uniqueName(name string, requests map[string]*config.Request) string {
+	result := name
+	for i := 2; requests[result] != nil; i++ {
+		result = fmt.Sprintf("%s%d", name, i)
+	}
+	return result
+}
+
+// keyPairValue returns the value of a key pair as a string, values may be any JSON type
+func keyPairValue(pair *postmanKeyPair) string {
+	switch value := pair.Value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
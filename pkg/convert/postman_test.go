@@ -0,0 +1,132 @@
+package convert
+
+import "testing"
+
+// testPostmanCollection uses folders, collection and folder auth, a base URL variable, path variables and raw bodies
+const testPostmanCollection = `{
+	"info": {"name": "Jobs", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+	"auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{accessToken}}", "type": "string"}]},
+	"variable": [
+		{"key": "baseUrl", "value": "https://jobs.example.com:8443/api"},
+		{"key": "accessToken", "value": "abc"}
+	],
+	"item": [
+		{
+			"name": "Jobs",
+			"item": [
+				{
+					"name": "Create job",
+					"request": {
+						"method": "POST",
+						"header": [
+							{"key": "Content-Type", "value": "application/json"},
+							{"key": "X-Debug", "value": "1", "disabled": true}
+						],
+						"body": {"mode": "raw", "raw": "{\"name\": \"{{jobName}}\", \"priority\": 1}"},
+						"url": {"raw": "{{baseUrl}}/jobs/:jobId", "variable": [{"key": "jobId", "value": "{{jobId}}"}]}
+					},
+					"response": [{"code": 201}]
+				}
+			]
+		},
+		{
+			"name": "Health",
+			"auth": {"type": "apikey", "apikey": [{"key": "key", "value": "api_key"}, {"key": "value", "value": "{{apiKey}}"}, {"key": "in", "value": "query"}]},
+			"request": {"method": "GET", "url": "http://{{statusHost}}/health?verbose=true"}
+		},
+		{"name": "Patch job", "request": {"method": "PATCH", "url": "{{baseUrl}}/jobs/1"}}
+	]
+}`
+
+// TestFromPostman1 ensures requests and services are generated with variables converted into env references
+func TestFromPostman1(t *testing.T) {
+	cfg, warnings, err := FromPostman([]byte(testPostmanCollection))
+	if err != nil {
+		t.Fatalf("Error Encountered Importing Collection: %s", err.Error())
+	}
+
+	create := cfg.Requests["jobsCreateJob"]
+	if create == nil {
+		t.Fatalf("Request In Folder Not Imported: %v", cfg.Requests)
+	}
+	if create.URL != "/api/jobs/${JOB_ID}" || create.Method != "post" || create.Protocol != "https" {
+		t.Errorf("Incorrect Request: %s %s %s", create.Protocol, create.Method, create.URL)
+	}
+	if create.Headers["Authorization"] != "Bearer ${ACCESS_TOKEN}" {
+		t.Errorf("Collection Auth Not Inherited: %v", create.Headers)
+	}
+	if _, found := create.Headers["X-Debug"]; found {
+		t.Errorf("Disabled Header Imported")
+	}
+	if create.Body["name"] != "${JOB_NAME}" {
+		t.Errorf("Body Variable Not Converted: %v", create.Body)
+	}
+	if create.ExpectedResponse.StatusCode != 201 {
+		t.Errorf("Expected Status Not Read From Saved Response: %d", create.ExpectedResponse.StatusCode)
+	}
+
+	if service := cfg.Services["baseUrl"]; service == nil || service.Hostname != "jobs.example.com" || service.Port != 8443 {
+		t.Errorf("Base URL Service Incorrect: %v", cfg.Services)
+	}
+
+	health := cfg.Requests["health"]
+	if health == nil || health.URL != "/health?verbose=true&api_key=${API_KEY}" {
+		t.Errorf("API Key Query Auth Not Applied: %v", health)
+	}
+	if service := cfg.Services["statusHost"]; service == nil || service.Hostname != "${STATUS_HOST}" || service.Port != 80 {
+		t.Errorf("Host Variable Service Incorrect: %v", cfg.Services)
+	}
+
+	if cfg.Requests["patchJob"] != nil || len(warnings) != 1 {
+		t.Errorf("Unsupported Method Not Skipped: %v", warnings)
+	}
+	if cfg.Endpoints["/"]["get"] == nil {
+		t.Errorf("Endpoint Not Added For The Config To Load: %v", cfg.Endpoints)
+	}
+}
+
+// TestFromPostman2 ensures collections with an unsupported schema are rejected
+func TestFromPostman2(t *testing.T) {
+	collection := `{"info": {"schema": "https://schema.getpostman.com/json/collection/v1.0.0/collection.json"}, "item": []}`
+
+	if _, _, err := FromPostman([]byte(collection)); err == nil {
+		t.Errorf("Unsupported Schema Returned No Error")
+	}
+}
+
+// TestFromPostman3 ensures variables used as JSON values are quoted, and bodies which cannot be imported are warned of
+func TestFromPostman3(t *testing.T) {
+	collection := `{
+		"info": {"name": "Jobs", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+		"item": [
+			{"name": "Bare", "request": {"method": "POST", "url": "http://jobs/a", "body": {"mode": "raw", "raw": "{\"count\": {{count}}, \"note\": \"a {{x}} \\\" b\"}"}}},
+			{"name": "Broken", "request": {"method": "POST", "url": "http://jobs/b", "body": {"mode": "raw", "raw": "[1, 2]"}}},
+			{"name": "Form", "request": {"method": "POST", "url": "http://jobs/c", "body": {"mode": "urlencoded", "raw": "a=1"}}}
+		]
+	}`
+
+	cfg, warnings, err := FromPostman([]byte(collection))
+	if err != nil {
+		t.Fatalf("Error Encountered Importing Collection: %s", err.Error())
+	}
+
+	bare := cfg.Requests["bare"]
+	if bare == nil || bare.Body["count"] != "${COUNT}" || bare.Body["note"] != `a ${X} " b` {
+		t.Errorf("Bare Body Variable Not Quoted: %v", bare)
+	}
+	if cfg.Requests["broken"].Body != nil || cfg.Requests["form"].Body != nil {
+		t.Errorf("Invalid Bodies Were Imported")
+	}
+	if len(warnings) != 3 {
+		t.Errorf("Expected 3 Warnings, Got %v", warnings)
+	}
+}
+
+// TestEnvName1 ensures variable names are converted into environment variable names
+func TestEnvName1(t *testing.T) {
+	for input, expected := range map[string]string{"baseUrl": "BASE_URL", "api-key": "API_KEY", "HOST": "HOST", "v2Token": "V2_TOKEN"} {
+		if result := envName(input); result != expected {
+			t.Errorf("Incorrect Env Name For %s: %s", input, result)
+		}
+	}
+}